@@ -0,0 +1,71 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"context"
+	"sync"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+// All retries each of the keyed attempts independently in its own retry cycle
+// scheduled by c, and returns the errors of the cycles that failed by key. The
+// cycles run concurrently, but share a single budget: if c has a timeout (see
+// [Cycler.Timeout]), all of them must finish before that timeout passes from
+// the time All was called, in addition to the deadline of ctx. Likewise, if c
+// has a limit on attempts (see [Cycler.Limit]), the retries it permits are
+// shared among all cycles, each of which makes at least its initial attempt.
+// Cycles still running when the shared deadline passes are cancelled. This is
+// useful for fan-out writes that must collectively finish by a deadline. The
+// returned map is nil if all cycles succeeded.
+func All(
+	ctx context.Context,
+	c *Cycler,
+	attempts map[string]AttemptFunc,
+) map[string]error {
+	limit, _ := backoff.MaxAttempts(c.build(ctx, newCycle(0)))
+	pool := newBudget(budgetFrom(ctx), c.Clock.Time(), limit, c.timeout)
+	ctx = context.WithValue(ctx, budgetKey{}, pool)
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	var (
+		mu   sync.Mutex // guards errs
+		errs map[string]error
+		wg   sync.WaitGroup
+	)
+	for key, attempt := range attempts {
+		wg.Add(1)
+		go func(key string, attempt AttemptFunc) {
+			defer wg.Done()
+			if err := c.TryWithContext(ctx, attempt); err != nil {
+				mu.Lock()
+				if errs == nil {
+					errs = make(map[string]error)
+				}
+				errs[key] = err
+				mu.Unlock()
+			}
+		}(key, attempt)
+	}
+	wg.Wait()
+	return errs
+}
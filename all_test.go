@@ -0,0 +1,109 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestAll(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Limit(3)
+
+	errs := retry.All(context.Background(), cycler, map[string]retry.AttemptFunc{
+		"a": func(n int) error { return nil },
+		"b": func(n int) error {
+			if n < 2 {
+				return ErrTest
+			}
+			return nil
+		},
+		"c": func(n int) error { return retry.ForceExit(ErrTest) },
+	})
+
+	if len(errs) != 1 || !errors.Is(errs["c"], ErrTest) {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestAll_Success(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+
+	errs := retry.All(context.Background(), cycler, map[string]retry.AttemptFunc{
+		"a": func(n int) error { return nil },
+	})
+
+	if errs != nil {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestAll_SharedLimit(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Limit(3)
+
+	var mu sync.Mutex
+	attempts := 0
+	fail := func(n int) error {
+		mu.Lock()
+		attempts++
+		mu.Unlock()
+		return ErrTest
+	}
+
+	errs := retry.All(context.Background(), cycler, map[string]retry.AttemptFunc{
+		"a": fail,
+		"b": fail,
+	})
+
+	for _, key := range []string{"a", "b"} {
+		if !errors.Is(errs[key], ErrTest) {
+			t.Errorf("unexpected error for %q: %v", key, errs[key])
+		}
+	}
+	// two initial attempts plus two shared retries
+	if attempts != 4 {
+		t.Errorf("got %d attempts, want 4", attempts)
+	}
+}
+
+func TestAll_SharedDeadline(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Hour))
+	cycler.Timeout(50 * time.Millisecond)
+
+	start := time.Now()
+	errs := retry.All(context.Background(), cycler, map[string]retry.AttemptFunc{
+		"a": func(n int) error { return ErrTest },
+		"b": func(n int) error { return ErrTest },
+	})
+
+	if elapsed := time.Since(start); elapsed > 1*time.Second {
+		t.Errorf("elapsed %s, want about 50ms", elapsed)
+	}
+	for _, key := range []string{"a", "b"} {
+		if !errors.Is(errs[key], retry.ErrTimeout) {
+			t.Errorf("unexpected error for %q: %v", key, errs[key])
+		}
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"sync"
+)
+
+// An AuditFunc receives a description of a misuse detected in audit mode,
+// including the relevant goroutine stacks. See [Cycler.Audit].
+type AuditFunc func(violation string)
+
+// PanicAudit is an [AuditFunc] that panics with the violation.
+func PanicAudit(violation string) {
+	panic(violation)
+}
+
+// audit detects unsafe concurrent use of a cycler.
+type audit struct {
+	mu     sync.Mutex
+	report AuditFunc // receives violations
+	owner  uint64    // goroutine that configured the cycler first
+	stack  []byte    // stack of the first configuration
+	active int       // number of running cycles
+}
+
+// touch records a configuration change, and reports it if the cycler was
+// configured by another goroutine before, or if cycles are running.
+func (a *audit) touch() {
+	if a == nil {
+		return
+	}
+	id := goid()
+	stack := debug.Stack()
+	a.mu.Lock()
+	var v string
+	switch {
+	case a.active > 0:
+		v = fmt.Sprintf(
+			"retry: cycler configured while %d cycle(s) are running\n\n%s",
+			a.active, stack,
+		)
+	case a.owner == 0:
+		a.owner, a.stack = id, stack
+	case a.owner != id:
+		v = fmt.Sprintf(
+			"retry: cycler configured by goroutines %d and %d\n\n%s\n%s",
+			a.owner, id, a.stack, stack,
+		)
+	}
+	a.mu.Unlock()
+	if v != "" {
+		a.report(v)
+	}
+}
+
+// enter records the start of a cycle.
+func (a *audit) enter() {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	a.active++
+	a.mu.Unlock()
+}
+
+// leave records the end of a cycle.
+func (a *audit) leave() {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	a.active--
+	a.mu.Unlock()
+}
+
+// goid returns the id of the calling goroutine, as found in the header of its
+// stack trace, or 0 if it cannot be determined.
+func goid() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// Audit enables audit mode, a debugging aid that detects unsafe concurrent use
+// of the cycler. Configuring a cycler is not safe for concurrent use, yet
+// shared cyclers are easily configured from several goroutines by mistake. In
+// audit mode, the cycler reports to fn whenever its configuration methods are
+// called from a different goroutine than the first such call, or while retry
+// cycles are running. The report includes the goroutine stacks involved. Use
+// [PanicAudit] to fail fast. Changes to exported fields are not detected.
+// Audit mode is costly and should be enabled in tests or during development
+// only. If fn is nil, audit mode is disabled.
+func (c *Cycler) Audit(fn AuditFunc) {
+	if fn == nil {
+		c.audit = nil
+		return
+	}
+	c.audit = &audit{report: fn}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+type violations struct {
+	mu sync.Mutex
+	vs []string
+}
+
+func (v *violations) report(violation string) {
+	v.mu.Lock()
+	v.vs = append(v.vs, violation)
+	v.mu.Unlock()
+}
+
+func TestCycler_Audit(t *testing.T) {
+	var v violations
+
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Audit(v.report)
+	cycler.Limit(3)
+	cycler.Jitter(0.5)
+
+	if len(v.vs) != 0 {
+		t.Fatalf("unexpected violations: %v", v.vs)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		cycler.Limit(5)
+	}()
+	wg.Wait()
+
+	if len(v.vs) != 1 || !strings.Contains(v.vs[0], "goroutines") {
+		t.Errorf("unexpected violations: %v", v.vs)
+	}
+}
+
+func TestCycler_Audit_Running(t *testing.T) {
+	var v violations
+
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Audit(v.report)
+	cycler.Limit(2)
+
+	_ = cycler.Try(func(n int) error {
+		cycler.History(1)
+		return nil
+	})
+	_ = cycler.Try(func(n int) error { return nil })
+
+	if len(v.vs) != 1 || !strings.Contains(v.vs[0], "running") {
+		t.Errorf("unexpected violations: %v", v.vs)
+	}
+}
+
+func TestCycler_Audit_Panic(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Audit(retry.PanicAudit)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	_ = cycler.Try(func(n int) error {
+		cycler.Limit(1)
+		return nil
+	})
+}
+
+func TestCycler_Audit_Disabled(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Audit(retry.PanicAudit)
+	cycler.Audit(nil)
+
+	_ = cycler.Try(func(n int) error {
+		cycler.Limit(1)
+		return nil
+	})
+}
@@ -0,0 +1,178 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+// buckets is the number of buckets into which the window of an
+// [Availability] is divided.
+const buckets = 10
+
+// bucket counts the outcomes of attempts within a slice of time.
+type bucket struct {
+	start     time.Time // start of the slice
+	successes int
+	failures  int
+}
+
+// An Availability measures the availability of a dependency as the fraction
+// of successful attempts within a rolling time window. Cyclers use it to shed
+// load once the availability drops below a threshold, see
+// [Cycler.Availability]. It is safe for concurrent use, and can be shared
+// among all cyclers calling the same dependency. Use [NewAvailability] to
+// create a new instance.
+//
+// The Clock of an availability determines the current time. If nil, the system
+// clock is used. Cyclers consult the availability by means of their own Clock
+// instead.
+type Availability struct {
+	Clock     backoff.Clock
+	mu        sync.Mutex
+	width     time.Duration // width of a bucket
+	threshold float64       // minimum availability
+	min       int           // minimum number of samples
+	buckets   [buckets]bucket
+}
+
+// NewAvailability creates a new [Availability] that measures over the given
+// rolling window, and considers the dependency available as long as the
+// fraction of successful attempts is at least threshold. Below min samples in
+// the window, the dependency is always considered available, which avoids
+// hasty decisions based on too few attempts. The function panics if
+// window <= 0, or if threshold is not in [0,1].
+func NewAvailability(
+	window time.Duration,
+	threshold float64,
+	min int,
+) *Availability {
+	switch {
+	case window <= 0:
+		panic(fmt.Sprintf("window = %s, must be > 0", window))
+	case threshold < 0 || threshold > 1:
+		panic(fmt.Sprintf("threshold %f not in [0,1]", threshold))
+	}
+	width := window / buckets
+	if width <= 0 {
+		width = 1
+	}
+	return &Availability{
+		width:     width,
+		threshold: threshold,
+		min:       min,
+	}
+}
+
+// clock returns the Clock of a, or the system clock if there is none.
+func (a *Availability) clock() backoff.Clock {
+	if a.Clock == nil {
+		return now
+	}
+	return a.Clock
+}
+
+// current returns the bucket for now, resetting it if it is stale.
+func (a *Availability) current(now time.Time) *bucket {
+	start := now.Truncate(a.width)
+	// index slices by floored division, which stays non-negative for times
+	// before 1970, including the zero time
+	ns, w := start.UnixNano(), int64(a.width)
+	i := ns / w
+	if ns%w < 0 {
+		i--
+	}
+	i %= buckets
+	if i < 0 {
+		i += buckets
+	}
+	b := &a.buckets[i]
+	if !b.start.Equal(start) {
+		*b = bucket{start: start}
+	}
+	return b
+}
+
+// Record registers the outcome of an attempt.
+func (a *Availability) Record(success bool) {
+	a.record(a.clock().Time(), success)
+}
+
+// record implements [Availability.Record] for an attempt at time now.
+func (a *Availability) record(now time.Time, success bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	b := a.current(now)
+	if success {
+		b.successes++
+	} else {
+		b.failures++
+	}
+}
+
+// Ratio returns the fraction of successful attempts within the window, along
+// with the total number of attempts it is based on. If no attempts were
+// recorded, the ratio is 1.
+func (a *Availability) Ratio() (ratio float64, samples int) {
+	return a.ratio(a.clock().Time())
+}
+
+// ratio implements [Availability.Ratio] at time now.
+func (a *Availability) ratio(now time.Time) (ratio float64, samples int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	oldest := now.Truncate(a.width).Add(-(buckets - 1) * a.width)
+	successes := 0
+	for _, b := range a.buckets {
+		if b.start.Before(oldest) {
+			continue
+		}
+		successes += b.successes
+		samples += b.successes + b.failures
+	}
+	if samples == 0 {
+		return 1, 0
+	}
+	return float64(successes) / float64(samples), samples
+}
+
+// Available reports whether the dependency is considered available.
+func (a *Availability) Available() bool {
+	return a.available(a.clock().Time())
+}
+
+// available implements [Availability.Available] at time now.
+func (a *Availability) available(now time.Time) bool {
+	ratio, samples := a.ratio(now)
+	return samples < a.min || ratio >= a.threshold
+}
+
+// Availability makes the cycler record the outcome of every attempt in a, and
+// skip retries while the measured availability of the dependency is below the
+// threshold of a. Retry cycles then give up after the first failed attempt
+// with [LimitReached], shedding load from a dependency that is struggling
+// anyway. Retries resume automatically as the availability recovers, which is
+// observed through the attempts that are still made. If a is nil, no such
+// tracking will be performed.
+func (c *Cycler) Availability(a *Availability) {
+	c.audit.touch()
+	c.avail = a
+}
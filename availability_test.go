@@ -0,0 +1,105 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestAvailability(t *testing.T) {
+	a := retry.NewAvailability(1*time.Hour, 0.5, 4)
+
+	if !a.Available() {
+		t.Error("expected availability without samples")
+	}
+	a.Record(false)
+	a.Record(false)
+	a.Record(false)
+	if !a.Available() {
+		t.Error("expected availability below the minimum number of samples")
+	}
+	a.Record(true)
+	if ratio, samples := a.Ratio(); ratio != 0.25 || samples != 4 {
+		t.Errorf("got (%f, %d), want (0.25, 4)", ratio, samples)
+	}
+	if a.Available() {
+		t.Error("expected unavailability")
+	}
+	a.Record(true)
+	a.Record(true)
+	if !a.Available() {
+		t.Error("expected availability to recover")
+	}
+}
+
+func TestAvailability_Window(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	a := retry.NewAvailability(20*time.Millisecond, 1, 1)
+	a.Clock = backoff.ClockFunc(func() time.Time { return now })
+
+	a.Record(false)
+	if a.Available() {
+		t.Error("expected unavailability")
+	}
+	now = now.Add(30 * time.Millisecond)
+	if !a.Available() {
+		t.Error("expected old samples to expire")
+	}
+}
+
+func TestAvailability_PreEpoch(t *testing.T) {
+	for _, start := range []time.Time{
+		{},
+		time.Date(1969, 12, 31, 23, 59, 59, 995_000_000, time.UTC),
+	} {
+		now := start
+		a := retry.NewAvailability(100*time.Millisecond, 1, 1)
+		a.Clock = backoff.ClockFunc(func() time.Time { return now })
+
+		// one sample per bucket, spanning the epoch for the second start
+		for i := 0; i < 10; i++ {
+			a.Record(i%2 == 0)
+			now = now.Add(10 * time.Millisecond)
+		}
+		now = now.Add(-10 * time.Millisecond)
+		if ratio, samples := a.Ratio(); ratio != 0.5 || samples != 10 {
+			t.Errorf("%s: got (%f, %d), want (0.5, 10)", start, ratio, samples)
+		}
+	}
+}
+
+func TestCycler_Availability(t *testing.T) {
+	a := retry.NewAvailability(1*time.Hour, 0.5, 2)
+
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Availability(a)
+	cycler.Limit(5)
+
+	// the first cycle drives the availability down
+	if act := attempts(cycler); act != 2 {
+		t.Errorf("got %d attempts, want 2", act)
+	}
+	// subsequent cycles skip retries
+	if act := attempts(cycler); act != 1 {
+		t.Errorf("got %d attempts, want 1", act)
+	}
+}
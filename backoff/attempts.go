@@ -0,0 +1,64 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff
+
+// MaxAttempts reports the maximum number of attempts that strategy permits in a
+// retry cycle, including the initial attempt. The bound is derived from the
+// [Limit] decorators found in the chain obtained by repeatedly calling
+// [Unwrap], taking [SkipFirst] and [MinAttempts] into account, and from a
+// chain ending in [Once]. If no such bound exists, the second return value is
+// false. Note that time-based decorators such as [Timeout] may end cycles
+// earlier. Generic wrappers can use the bound to pre-allocate buffers or to
+// compute budgets per attempt.
+func MaxAttempts(strategy Strategy) (int, bool) {
+	max, ok := 0, false
+	shift := 0 // number of attempts added by enclosing decorators
+	for s := strategy; s != nil; s = Unwrap(s) {
+		n := 0
+		switch s := s.(type) {
+		case *skipFirst:
+			shift++
+			continue
+		case *limit:
+			n = s.n
+		case *minAttempts:
+			// the inner bound is raised to at least k
+			inner, bounded := MaxAttempts(s.strategy)
+			if !bounded {
+				return max, ok
+			}
+			if n = inner; n < s.k {
+				n = s.k
+			}
+			if n += shift; !ok || n < max {
+				max, ok = n, true
+			}
+			return max, ok
+		case *constant:
+			if s.d != Exit {
+				continue
+			}
+			n = 1
+		default:
+			continue
+		}
+		if n += shift; !ok || n < max {
+			max, ok = n, true
+		}
+	}
+	return max, ok
+}
@@ -0,0 +1,53 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestMaxAttempts(t *testing.T) {
+	c := backoff.Constant(1 * time.Second)
+	for i, test := range []struct {
+		strategy backoff.Strategy
+		n        int
+		ok       bool
+	}{
+		{c, 0, false},
+		{backoff.Limit(c, 3), 3, true},
+		{backoff.LimitRetries(c, 3), 4, true},
+		{backoff.Cap(backoff.Limit(backoff.Limit(c, 5), 2), time.Second), 2, true},
+		{backoff.Limit(backoff.Limit(c, 2), 5), 2, true},
+		{backoff.SkipFirst(backoff.Limit(c, 3)), 4, true},
+		{backoff.Limit(backoff.SkipFirst(c), 3), 3, true},
+		{backoff.Once, 1, true},
+		{backoff.SkipFirst(backoff.Once), 2, true},
+		{backoff.Timeout(c, time.Second, clock(time.Now())), 0, false},
+		{backoff.MinAttempts(backoff.Limit(c, 2), 3), 3, true},
+		{backoff.MinAttempts(backoff.Limit(c, 5), 3), 5, true},
+		{backoff.Limit(backoff.MinAttempts(backoff.Limit(c, 2), 4), 3), 3, true},
+		{backoff.MinAttempts(c, 3), 0, false},
+	} {
+		n, ok := backoff.MaxAttempts(test.strategy)
+		if n != test.n || ok != test.ok {
+			t.Errorf("#%d: got (%d, %t), want (%d, %t)", i, n, ok, test.n, test.ok)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package backofftest provides utilities for testing custom backoff
+// strategies.
+//
+// [AssertSchedule] compares the delays of a [backoff.Strategy] against a golden
+// schedule. [Check] and [CheckMonotone] verify that a strategy honors the
+// contracts of the backoff package. [CheckDecorator] and [VerifyDecorator] do
+// the same for custom decorators. Since the Check functions report violations
+// as errors rather than failing a test, they can be used within fuzz targets as
+// well.
+package backofftest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+// AssertSchedule reports an error through t unless strategy produces exactly
+// the delays in want for the attempts n = 1 to n = len(want). Use
+// [backoff.Exit] to assert the end of the cycle. The start time passed to
+// strategy is the time of the call.
+func AssertSchedule(
+	t testing.TB,
+	strategy backoff.Strategy,
+	want []time.Duration,
+) {
+	t.Helper()
+	start := time.Now()
+	for i, exp := range want {
+		n := i + 1
+		if act := strategy.Delay(n, start); act != exp {
+			t.Errorf("delay #%d was %s, want %s", n, act, exp)
+		}
+	}
+}
+
+// Check verifies that strategy honors the contracts of the backoff package for
+// the attempts n = 1 to n = k: delays must be non-negative unless they equal
+// [backoff.Exit], and once the strategy returns [backoff.Exit], it must keep
+// doing so. The first violation found is returned as an error.
+func Check(strategy backoff.Strategy, k int) error {
+	return check(strategy, k, time.Now())
+}
+
+// check implements [Check] for a cycle that started at start.
+func check(strategy backoff.Strategy, k int, start time.Time) error {
+	exited := false
+	for n := 1; n <= k; n++ {
+		delay := strategy.Delay(n, start)
+		switch {
+		case delay == backoff.Exit:
+			exited = true
+		case exited:
+			return fmt.Errorf("delay #%d was %s after exit", n, delay)
+		case delay < 0:
+			return fmt.Errorf("delay #%d was negative: %s", n, delay)
+		}
+	}
+	return nil
+}
+
+// CheckMonotone works like [Check], but additionally verifies that delays
+// never decrease, as expected from most growing strategies without jitter.
+func CheckMonotone(strategy backoff.Strategy, k int) error {
+	if err := Check(strategy, k); err != nil {
+		return err
+	}
+	start := time.Now()
+	var prev time.Duration
+	for n := 1; n <= k; n++ {
+		delay := strategy.Delay(n, start)
+		if delay == backoff.Exit {
+			break
+		}
+		if delay < prev {
+			return fmt.Errorf(
+				"delay #%d decreased from %s to %s",
+				n, prev, delay,
+			)
+		}
+		prev = delay
+	}
+	return nil
+}
+
+// AssertInvariants reports the error returned by [Check] through t, if any.
+func AssertInvariants(t testing.TB, strategy backoff.Strategy, k int) {
+	t.Helper()
+	if err := Check(strategy, k); err != nil {
+		t.Error(err)
+	}
+}
+
+// epoch is the start time that CheckDecorator passes to strategies, such that
+// its outcome does not depend on the time of the call.
+var epoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// bases are the strategies that decorators are applied to in CheckDecorator.
+var bases = []struct {
+	name     string
+	strategy backoff.Strategy
+}{
+	{"constant(0)", backoff.Constant(0)},
+	{"constant(1s)", backoff.Constant(1 * time.Second)},
+	{"linear(1ms,1ms)", backoff.Linear(1*time.Millisecond, 1*time.Millisecond)},
+	{"exponential(1ms,2)", backoff.Exponential(1*time.Millisecond, 2)},
+}
+
+// CheckDecorator verifies that the strategies produced by wrap honor the
+// assumptions the retry cycler makes about them, for the attempts n = 1 to
+// n = k:
+//
+//   - the decorated strategies satisfy the contracts verified by [Check] for
+//     a variety of wrapped strategies,
+//   - the decorated strategy exits if the wrapped strategy does, i.e., the end
+//     of the cycle may be postponed by a few attempts, as [backoff.SkipFirst]
+//     does, but never suppressed, and
+//   - the delays are stateless, i.e., they do not depend on the order in which
+//     they are requested, nor on concurrent requests.
+//
+// Since the same delay is requested repeatedly, decorators that inject
+// randomness must be checked with a constant source of random numbers, such as
+// func() float64 { return 0.5 }; a merely seeded source yields a different
+// number on every call. All delays are requested with the same fixed start
+// time. The first violation found is returned as an error.
+func CheckDecorator(wrap backoff.Decorator, k int) error {
+	start := epoch
+	for _, base := range bases {
+		if err := check(wrap(base.strategy), k, start); err != nil {
+			return fmt.Errorf("decorating %s: %w", base.name, err)
+		}
+	}
+
+	for m := 1; m <= k/2; m++ {
+		s := wrap(backoff.Limit(backoff.Constant(1*time.Millisecond), m))
+		if err := check(s, k, start); err != nil {
+			return fmt.Errorf("decorating a limit of %d attempts: %w", m, err)
+		}
+		if s.Delay(k, start) != backoff.Exit {
+			return fmt.Errorf(
+				"no exit within %d attempts, but the wrapped strategy "+
+					"exited after %d",
+				k, m,
+			)
+		}
+	}
+
+	s := wrap(backoff.Exponential(1*time.Millisecond, 2))
+	want := make([]time.Duration, k)
+	for n := 1; n <= k; n++ {
+		want[n-1] = s.Delay(n, start)
+	}
+	for n := k; n >= 1; n-- {
+		if delay := s.Delay(n, start); delay != want[n-1] {
+			return fmt.Errorf(
+				"delay #%d was %s when requested in reverse order, want %s",
+				n, delay, want[n-1],
+			)
+		}
+	}
+
+	// request delays concurrently, such that the race detector can catch
+	// unsynchronized state
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 1; n <= k; n++ {
+				if delay := s.Delay(n, start); delay != want[n-1] {
+					mu.Lock()
+					errs = append(errs, fmt.Errorf(
+						"delay #%d was %s when requested concurrently, want %s",
+						n, delay, want[n-1],
+					))
+					mu.Unlock()
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// VerifyDecorator reports the error returned by [CheckDecorator] through t, if
+// any. It examines the first 20 attempts, which suffices for most decorators.
+func VerifyDecorator(t testing.TB, wrap backoff.Decorator) {
+	t.Helper()
+	if err := CheckDecorator(wrap, 20); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backofftest_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+	"github.com/deep-rent/retry/backoff/backofftest"
+)
+
+// recorder captures the errors reported through it.
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Error(args ...interface{}) {
+	r.errs = append(r.errs, fmt.Sprint(args...))
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+// stateful is a broken strategy that resumes after signalling exit.
+type stateful struct{}
+
+func (stateful) Delay(n int, start time.Time) time.Duration {
+	if n == 2 {
+		return backoff.Exit
+	}
+	return time.Second
+}
+
+// negative is a broken strategy that produces a negative delay.
+type negative struct{}
+
+func (negative) Delay(n int, start time.Time) time.Duration {
+	return -2 * time.Second
+}
+
+func TestAssertSchedule(t *testing.T) {
+	s := backoff.Limit(backoff.Linear(1*time.Second, 1*time.Second), 3)
+
+	backofftest.AssertSchedule(t, s, []time.Duration{
+		1 * time.Second,
+		2 * time.Second,
+		backoff.Exit,
+	})
+
+	r := &recorder{TB: t}
+	backofftest.AssertSchedule(r, s, []time.Duration{1 * time.Second, 3 * time.Second})
+	if len(r.errs) != 1 {
+		t.Errorf("got %d errors, want 1: %q", len(r.errs), r.errs)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	for i, test := range []struct {
+		strategy backoff.Strategy
+		ok       bool
+	}{
+		{backoff.Exponential(1*time.Second, 2), true},
+		{backoff.Limit(backoff.Constant(1*time.Second), 3), true},
+		{backoff.Jitter(backoff.Constant(1*time.Second), 0.5, rnd), true},
+		{stateful{}, false},
+		{negative{}, false},
+	} {
+		if err := backofftest.Check(test.strategy, 10); (err == nil) != test.ok {
+			t.Errorf("#%d: unexpected result: %v", i, err)
+		}
+	}
+}
+
+func TestCheckMonotone(t *testing.T) {
+	if err := backofftest.CheckMonotone(backoff.Linear(1*time.Second, 1*time.Second), 10); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := backofftest.CheckMonotone(backoff.Linear(5*time.Second, -1*time.Second), 10); err == nil {
+		t.Errorf("expected error for shrinking delays")
+	}
+}
+
+func TestAssertInvariants(t *testing.T) {
+	r := &recorder{TB: t}
+	backofftest.AssertInvariants(r, stateful{}, 3)
+	if len(r.errs) != 1 {
+		t.Errorf("got %d errors, want 1: %q", len(r.errs), r.errs)
+	}
+}
+
+func FuzzCheck(f *testing.F) {
+	f.Add(int64(time.Second), int64(time.Second))
+	f.Fuzz(func(t *testing.T, d int64, k int64) {
+		if d < 0 || d > int64(time.Hour) || k < 0 || k > int64(time.Hour) {
+			t.Skip()
+		}
+		s := backoff.Linear(time.Duration(d), time.Duration(k))
+		if err := backofftest.CheckMonotone(s, 50); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func rnd() float64 { return 0.5 }
+
+// resurrected is a broken strategy that keeps retrying after the wrapped
+// strategy exited.
+type resurrected struct{ backoff.Strategy }
+
+func (r resurrected) Delay(n int, start time.Time) time.Duration {
+	if delay := r.Strategy.Delay(n, start); delay != backoff.Exit {
+		return delay
+	}
+	return time.Second
+}
+
+func resurrect(s backoff.Strategy) backoff.Strategy { return resurrected{s} }
+
+// counter is a broken decorator whose delays depend on previous calls.
+type counter struct {
+	mu    sync.Mutex
+	calls time.Duration
+}
+
+func (c *counter) Delay(n int, start time.Time) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	return c.calls
+}
+
+func TestCheckDecorator(t *testing.T) {
+	for i, test := range []struct {
+		wrap backoff.Decorator
+		ok   bool
+	}{
+		{backoff.WithCap(1 * time.Second), true},
+		{backoff.WithLimit(3), true},
+		{backoff.WithJitter(0.5, func() float64 { return 0.5 }), true},
+		{backoff.WithSkipFirst(), true},
+		{resurrect, false},
+		{func(backoff.Strategy) backoff.Strategy { return negative{} }, false},
+		{func(backoff.Strategy) backoff.Strategy { return &counter{} }, false},
+	} {
+		err := backofftest.CheckDecorator(test.wrap, 10)
+		if (err == nil) != test.ok {
+			t.Errorf("#%d: unexpected result: %v", i, err)
+		}
+	}
+}
+
+func TestVerifyDecorator(t *testing.T) {
+	backofftest.VerifyDecorator(t, backoff.WithCap(1*time.Second))
+
+	r := &recorder{TB: t}
+	backofftest.VerifyDecorator(r, resurrect)
+	if len(r.errs) != 1 {
+		t.Errorf("got %d errors, want 1: %q", len(r.errs), r.errs)
+	}
+}
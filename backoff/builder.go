@@ -0,0 +1,107 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff
+
+import (
+	"math/rand"
+	"time"
+)
+
+// A Builder composes a backoff [Strategy] from a base strategy and a set of
+// decorators. Regardless of the order in which they are configured, the
+// decorators are applied in a canonical order: [SkipFirst] first, followed by
+// [Jitter], [Cap], [Limit] and [Timeout]. This guarantees, for instance, that
+// jittered delays never exceed the cap. Configuring the same decorator twice
+// overrides the previous setting. Use [Build] to create a new builder.
+type Builder struct {
+	base    Strategy
+	skip    bool
+	spread  float64
+	random  Random
+	max     time.Duration
+	limit   int
+	timeout time.Duration
+	clock   Clock
+}
+
+// Build starts building a backoff [Strategy] on top of the base strategy.
+func Build(base Strategy) *Builder {
+	return &Builder{
+		base:   base,
+		random: rand.Float64,
+		clock:  ClockFunc(time.Now),
+	}
+}
+
+// SkipFirst makes the first retry happen immediately, see [SkipFirst].
+func (b *Builder) SkipFirst() *Builder {
+	b.skip = true
+	return b
+}
+
+// Jitter configures random [Jitter] with the given spread factor.
+func (b *Builder) Jitter(spread float64) *Builder {
+	b.spread = spread
+	return b
+}
+
+// Random sets the random number generator used for [Builder.Jitter]. By
+// default, the generator of the math/rand package is used.
+func (b *Builder) Random(random Random) *Builder {
+	b.random = random
+	return b
+}
+
+// Cap configures a maximum delay, see [Cap].
+func (b *Builder) Cap(max time.Duration) *Builder {
+	b.max = max
+	return b
+}
+
+// Limit configures a maximum number of attempts, see [Limit].
+func (b *Builder) Limit(n int) *Builder {
+	b.limit = n
+	return b
+}
+
+// Timeout configures a maximum duration of retry cycles, see [Timeout].
+func (b *Builder) Timeout(limit time.Duration) *Builder {
+	b.timeout = limit
+	return b
+}
+
+// Clock sets the clock used for [Builder.Timeout]. By default, the system
+// clock is used.
+func (b *Builder) Clock(clock Clock) *Builder {
+	b.clock = clock
+	return b
+}
+
+// Strategy assembles the configured [Strategy]. The function panics if the
+// configuration is invalid, e.g. if the spread factor of [Builder.Jitter] is
+// not in [0,1).
+func (b *Builder) Strategy() Strategy {
+	s := b.base
+	if b.skip {
+		s = SkipFirst(s)
+	}
+	s = Jitter(s, b.spread, b.random)
+	s = Cap(s, b.max)
+	s = Limit(s, b.limit)
+	s = Timeout(s, b.timeout, b.clock)
+	return s
+}
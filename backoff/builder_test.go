@@ -0,0 +1,86 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestBuildOrder(t *testing.T) {
+	// decorators are configured in the "wrong" order
+	s := backoff.Build(backoff.Constant(1 * time.Second)).
+		Cap(1 * time.Second).
+		Jitter(0.5).
+		Random(random(0.75)).
+		Strategy()
+	act := s.Delay(1, time.Date(0, 0, 0, 0, 0, 0, 0, time.Local))
+
+	const exp = 1 * time.Second
+
+	if act != exp {
+		t.Errorf("delay was %s, want %s", act, exp)
+	}
+}
+
+func TestBuildLimit(t *testing.T) {
+	s := backoff.Build(backoff.Constant(1 * time.Second)).
+		Limit(2).
+		Jitter(0.5).
+		Strategy()
+	act := s.Delay(2, time.Date(0, 0, 0, 0, 0, 0, 0, time.Local))
+
+	exp := backoff.Exit
+
+	if act != exp {
+		t.Errorf("delay was %s, want %s", act, exp)
+	}
+}
+
+func TestBuildTimeout(t *testing.T) {
+	d1 := time.Date(0, 0, 0, 0, 0, 0, 0, time.Local)
+	d2 := time.Date(0, 0, 0, 0, 0, 2, 0, time.Local)
+
+	s := backoff.Build(backoff.Constant(1 * time.Second)).
+		Timeout(1 * time.Second).
+		Clock(clock(d2)).
+		Strategy()
+	act := s.Delay(1, d1)
+
+	exp := backoff.Exit
+
+	if act != exp {
+		t.Errorf("delay was %s, want %s", act, exp)
+	}
+}
+
+func TestBuildSkipFirst(t *testing.T) {
+	s := backoff.Build(backoff.Constant(1 * time.Second)).
+		Limit(2).
+		SkipFirst().
+		Strategy()
+
+	d := time.Date(0, 0, 0, 0, 0, 0, 0, time.Local)
+	if act := s.Delay(1, d); act != 0 {
+		t.Errorf("delay was %s, want 0s", act)
+	}
+	if act := s.Delay(2, d); act != backoff.Exit {
+		t.Errorf("delay was %s, want %s", act, backoff.Exit)
+	}
+}
@@ -26,13 +26,20 @@ type cap struct {
 }
 
 func (c *cap) Delay(n int, start time.Time) time.Duration {
-	delay := c.strategy.Delay(n, start)
+	delay, _ := c.Explain(n, start)
+	return delay
+}
+
+func (c *cap) Explain(n int, start time.Time) (time.Duration, Cause) {
+	delay, cause := Explain(c.strategy, n, start)
 	if delay > c.max {
-		return c.max
+		return c.max, cause
 	}
-	return delay
+	return delay, cause
 }
 
+func (c *cap) Unwrap() Strategy { return c.strategy }
+
 // Cap wraps a backoff [Strategy] to cap produced delays at the given maximum.
 // If max <= 0, no limit will be applied.
 func Cap(strategy Strategy, max time.Duration) Strategy {
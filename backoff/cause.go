@@ -0,0 +1,92 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff
+
+import "time"
+
+// A Cause tells which part of a composite [Strategy] ended a retry cycle. See
+// [Explain].
+type Cause int
+
+const (
+	// CauseUnknown indicates that the cause could not be determined, e.g.
+	// because the strategy did not signal [Exit].
+	CauseUnknown Cause = iota
+	// CauseLimit indicates that an attempt limit was reached, see [Limit].
+	CauseLimit
+	// CauseTimeout indicates that a timeout elapsed, see [Timeout].
+	CauseTimeout
+	// CauseSignal indicates that an external signal halted the cycle, see
+	// [StopIf].
+	CauseSignal
+	// CauseBase indicates that the innermost strategy signalled [Exit] by
+	// itself, e.g. [Once].
+	CauseBase
+)
+
+var causes = [...]string{
+	CauseUnknown: "unknown",
+	CauseLimit:   "limit",
+	CauseTimeout: "timeout",
+	CauseSignal:  "signal",
+	CauseBase:    "base",
+}
+
+func (c Cause) String() string {
+	if c < 0 || int(c) >= len(causes) {
+		return "unknown"
+	}
+	return causes[c]
+}
+
+// An Explainer is a [Strategy] that can tell why it signals [Exit]. All
+// decorators in this package implement it, and so should custom decorators
+// that either end a retry cycle by themselves, or wrap another strategy.
+type Explainer interface {
+	Strategy
+	// Explain works like Delay, but additionally returns the [Cause] if the
+	// delay is [Exit], or CauseUnknown otherwise. Exit conditions must be
+	// evaluated exactly once, as they would be by Delay.
+	Explain(n int, start time.Time) (time.Duration, Cause)
+}
+
+// Explain computes the delay of strategy after the n-th attempt of a retry
+// cycle that started at the given time, just like its Delay method would, and
+// tells which part of strategy made it signal [Exit], if it did. Each
+// [Explainer] in the chain of decorators reports the cause it is responsible
+// for, so that stacked exit conditions are told apart precisely, and none of
+// them is evaluated twice. A strategy that does not implement [Explainer] is
+// the cause if it does not wrap another strategy (see [Unwrap]), or else the
+// cause is unknown.
+func Explain(
+	strategy Strategy,
+	n int,
+	start time.Time,
+) (time.Duration, Cause) {
+	if e, ok := strategy.(Explainer); ok {
+		return e.Explain(n, start)
+	}
+	delay := strategy.Delay(n, start)
+	switch {
+	case delay != Exit:
+		return delay, CauseUnknown
+	case Unwrap(strategy) == nil:
+		return Exit, CauseBase
+	default:
+		return Exit, CauseUnknown
+	}
+}
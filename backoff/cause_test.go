@@ -0,0 +1,109 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+func never() bool { return false }
+
+func TestExplain(t *testing.T) {
+	c := backoff.Constant(1 * time.Second)
+	past := time.Now().Add(-1 * time.Hour)
+	now := clock(time.Now())
+
+	for i, test := range []struct {
+		strategy backoff.Strategy
+		n        int
+		start    time.Time
+		exp      backoff.Cause
+	}{
+		{backoff.SkipFirst(c), 1, past, backoff.CauseUnknown},
+		{backoff.Limit(c, 2), 2, past, backoff.CauseLimit},
+		{backoff.Timeout(c, time.Minute, now), 1, past, backoff.CauseTimeout},
+		{backoff.Timeout(backoff.Limit(c, 2), time.Minute, now), 2, past, backoff.CauseTimeout},
+		{backoff.Limit(backoff.Timeout(c, time.Minute, now), 2), 2, past, backoff.CauseLimit},
+		{backoff.Cap(backoff.Limit(c, 2), time.Second), 2, past, backoff.CauseLimit},
+		{backoff.SkipFirst(backoff.Limit(c, 2)), 3, past, backoff.CauseLimit},
+		{backoff.StopIf(c, func() bool { return true }), 1, past, backoff.CauseSignal},
+		{backoff.Cap(backoff.Once, time.Second), 1, past, backoff.CauseBase},
+		{backoff.StopIf(backoff.Once, never), 1, past, backoff.CauseBase},
+	} {
+		d, act := backoff.Explain(test.strategy, test.n, test.start)
+		if act != test.exp {
+			t.Errorf("#%d: cause was %s, want %s", i, act, test.exp)
+		}
+		if exit := test.exp != backoff.CauseUnknown; exit != (d == backoff.Exit) {
+			t.Errorf("#%d: delay was %v", i, d)
+		}
+	}
+}
+
+// counting is a base strategy that counts its invocations.
+type counting struct {
+	calls int
+}
+
+func (c *counting) Delay(n int, start time.Time) time.Duration {
+	c.calls++
+	return backoff.Exit
+}
+
+func TestExplain_Once(t *testing.T) {
+	base := &counting{}
+	s := backoff.Cap(backoff.Limit(base, 5), time.Second)
+
+	if _, act := backoff.Explain(s, 1, time.Now()); act != backoff.CauseBase {
+		t.Errorf("cause was %s, want %s", act, backoff.CauseBase)
+	}
+	if base.calls != 1 {
+		t.Errorf("base was invoked %d times, want 1", base.calls)
+	}
+}
+
+func TestExplain_StopOn(t *testing.T) {
+	signal := make(chan struct{}, 2)
+	s := backoff.StopOn(backoff.Constant(time.Second), signal)
+	signal <- struct{}{}
+	signal <- struct{}{}
+
+	for i := 0; i < 2; i++ {
+		d, act := backoff.Explain(s, 1, time.Now())
+		if d != backoff.Exit || act != backoff.CauseSignal {
+			t.Errorf("#%d: got (%v, %s), want (Exit, %s)",
+				i, d, act, backoff.CauseSignal)
+		}
+	}
+	if d, act := backoff.Explain(s, 1, time.Now()); d == backoff.Exit {
+		t.Errorf("cycle was halted without signal, cause %s", act)
+	}
+}
+
+type opaque struct{ backoff.Strategy }
+
+func (o opaque) Unwrap() backoff.Strategy { return o.Strategy }
+
+func TestExplain_Opaque(t *testing.T) {
+	s := opaque{backoff.Limit(backoff.Constant(time.Second), 1)}
+	if _, act := backoff.Explain(s, 1, time.Now()); act != backoff.CauseUnknown {
+		t.Errorf("cause was %s, want %s", act, backoff.CauseUnknown)
+	}
+}
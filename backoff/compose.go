@@ -0,0 +1,99 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff
+
+import "time"
+
+// A Decorator wraps a backoff [Strategy] to adjust its behavior. Decorators can
+// be stored and passed around as data, and are applied using [Compose].
+type Decorator func(strategy Strategy) Strategy
+
+// Compose wraps base in the given decorators, such that a policy can be
+// assembled in one declarative expression. The decorators are applied in the
+// order given, so the first decorator wraps base directly, and the last one
+// ends up outermost. Unlike a [Builder], Compose does not enforce a canonical
+// order. Nil decorators are skipped.
+func Compose(base Strategy, decorators ...Decorator) Strategy {
+	s := base
+	for _, d := range decorators {
+		if d != nil {
+			s = d(s)
+		}
+	}
+	return s
+}
+
+// WithCap returns a [Decorator] that applies [Cap].
+func WithCap(max time.Duration) Decorator {
+	return func(strategy Strategy) Strategy {
+		return Cap(strategy, max)
+	}
+}
+
+// WithJitter returns a [Decorator] that applies [Jitter].
+func WithJitter(spread float64, random Random) Decorator {
+	return func(strategy Strategy) Strategy {
+		return Jitter(strategy, spread, random)
+	}
+}
+
+// WithLimit returns a [Decorator] that applies [Limit].
+func WithLimit(n int) Decorator {
+	return func(strategy Strategy) Strategy {
+		return Limit(strategy, n)
+	}
+}
+
+// WithLimitRetries returns a [Decorator] that applies [LimitRetries].
+func WithLimitRetries(n int) Decorator {
+	return func(strategy Strategy) Strategy {
+		return LimitRetries(strategy, n)
+	}
+}
+
+// WithTimeout returns a [Decorator] that applies [Timeout].
+func WithTimeout(limit time.Duration, clock Clock) Decorator {
+	return func(strategy Strategy) Strategy {
+		return Timeout(strategy, limit, clock)
+	}
+}
+
+// WithSkipFirst returns a [Decorator] that applies [SkipFirst].
+func WithSkipFirst() Decorator {
+	return SkipFirst
+}
+
+// WithTimeOfDay returns a [Decorator] that applies [TimeOfDay].
+func WithTimeOfDay(clock Clock, schedule ...Window) Decorator {
+	return func(strategy Strategy) Strategy {
+		return TimeOfDay(strategy, clock, schedule...)
+	}
+}
+
+// WithStopIf returns a [Decorator] that applies [StopIf].
+func WithStopIf(cond func() bool) Decorator {
+	return func(strategy Strategy) Strategy {
+		return StopIf(strategy, cond)
+	}
+}
+
+// WithStopOn returns a [Decorator] that applies [StopOn].
+func WithStopOn(signal <-chan struct{}) Decorator {
+	return func(strategy Strategy) Strategy {
+		return StopOn(strategy, signal)
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestCompose(t *testing.T) {
+	s := backoff.Compose(
+		backoff.Linear(1*time.Second, 1*time.Second),
+		backoff.WithSkipFirst(),
+		backoff.WithJitter(0.5, random(0)),
+		backoff.WithCap(2*time.Second),
+		nil,
+		backoff.WithLimit(4),
+	)
+
+	d := time.Date(0, 0, 0, 0, 0, 0, 0, time.Local)
+	for i, exp := range []time.Duration{
+		0,
+		500 * time.Millisecond,
+		1 * time.Second,
+		backoff.Exit,
+	} {
+		n := i + 1
+		act := s.Delay(n, d)
+
+		if act != exp {
+			t.Errorf("delay #%d was %s, want %s", n, act, exp)
+		}
+	}
+}
+
+func TestComposeTimeout(t *testing.T) {
+	d1 := time.Date(0, 0, 0, 0, 0, 0, 0, time.Local)
+	d2 := time.Date(0, 0, 0, 0, 0, 3, 0, time.Local)
+
+	s := backoff.Compose(
+		backoff.Constant(1*time.Second),
+		backoff.WithLimitRetries(5),
+		backoff.WithTimeout(2*time.Second, clock(d2)),
+	)
+	act := s.Delay(1, d1)
+
+	exp := backoff.Exit
+
+	if act != exp {
+		t.Errorf("delay was %s, want %s", act, exp)
+	}
+}
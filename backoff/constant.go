@@ -16,10 +16,7 @@
 
 package backoff
 
-import (
-	"fmt"
-	"time"
-)
+import "time"
 
 type constant struct {
 	d time.Duration
@@ -32,10 +29,16 @@ func (con *constant) Delay(n int, start time.Time) time.Duration {
 // Constant returns a backoff [Strategy] that always returns delay d. The
 // function panics if d < 0.
 func Constant(d time.Duration) Strategy {
+	return must(NewConstant(d))
+}
+
+// NewConstant works like [Constant], but returns an error wrapping
+// [ErrInvalid] instead of panicking if d < 0.
+func NewConstant(d time.Duration) (Strategy, error) {
 	if d < 0 {
-		panic(fmt.Sprintf("d = %s, must be >= 0", d))
+		return nil, invalid("d = %s, must be >= 0", d)
 	}
-	return &constant{d: d}
+	return &constant{d: d}, nil
 }
 
 // Once is a backoff [Strategy] that always returns Exit, i.e. exits after the
@@ -17,6 +17,7 @@
 package backoff_test
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -33,3 +34,25 @@ func TestConstant(t *testing.T) {
 		t.Errorf("delay was %s, want %s", act, exp)
 	}
 }
+
+func TestNewConstant(t *testing.T) {
+	if _, err := backoff.NewConstant(-1 * time.Second); !errors.Is(err, backoff.ErrInvalid) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	s, err := backoff.NewConstant(1 * time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if act := s.Delay(1, time.Now()); act != 1*time.Second {
+		t.Errorf("delay was %s, want 1s", act)
+	}
+}
+
+func TestConstantPanic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for d < 0")
+		}
+	}()
+	backoff.Constant(-1 * time.Second)
+}
@@ -25,9 +25,13 @@
 type exponential struct {
 	d time.Duration // initial delay
 	m float64       // exponential multiplier
+	k int           // attempt from which on the delay plateaus, or 0
 }
 
 func (exp *exponential) Delay(n int, start time.Time) time.Duration {
+	if exp.k > 0 && n > exp.k {
+		n = exp.k
+	}
 	return time.Duration(float64(exp.d) * math.Pow(exp.m, float64(n-1)))
 }
 
@@ -35,19 +39,41 @@ func (exp *exponential) Delay(n int, start time.Time) time.Duration {
 // grow (m > 1), or shrink (m < 1) by the factor m, starting from the
 // specified initial delay d. The function panics if d or m are negative.
 func Exponential(d time.Duration, m float64) Strategy {
+	return must(NewExponential(d, m))
+}
+
+// NewExponential works like [Exponential], but returns an error wrapping
+// [ErrInvalid] instead of panicking if d or m are negative.
+func NewExponential(d time.Duration, m float64) (Strategy, error) {
 	switch {
 	case d < 0:
-		panic(fmt.Sprintf("d = %s, must be >= 0", d))
-	case m < 0:
-		panic(fmt.Sprintf("m = %f, must be >= 0", m))
+		return nil, invalid("d = %s, must be >= 0", d)
+	case m < 0 || math.IsNaN(m):
+		return nil, invalid("m = %f, must be >= 0", m)
 	case d == 0 || m == 0:
-		return Constant(0)
+		return NewConstant(0)
 	case m == 1:
-		return Constant(d)
+		return NewConstant(d)
 	default:
 		return &exponential{
 			d: d,
 			m: m,
-		}
+		}, nil
+	}
+}
+
+// BoundedExponential works like [Exponential], but the exponent stops growing
+// after the k-th attempt, such that the delay plateaus at d * m^(k-1) by
+// construction. Unlike [Cap], the bound does not depend on the order in which
+// decorators are applied, so [Jitter] wrapped around the strategy stays within
+// the intended band. The function panics if d or m are negative, or if k < 1.
+func BoundedExponential(d time.Duration, m float64, k int) Strategy {
+	if k < 1 {
+		panic(fmt.Sprintf("k = %d, must be >= 1", k))
+	}
+	s := Exponential(d, m)
+	if exp, ok := s.(*exponential); ok {
+		exp.k = k
 	}
+	return s
 }
@@ -17,6 +17,8 @@
 package backoff_test
 
 import (
+	"errors"
+	"math"
 	"testing"
 	"time"
 
@@ -60,3 +62,54 @@ func TestExponentialDecrease(t *testing.T) {
 		}
 	}
 }
+
+func TestBoundedExponential(t *testing.T) {
+	s := backoff.BoundedExponential(1*time.Second, 2, 3)
+
+	d := time.Date(0, 0, 0, 0, 0, 0, 0, time.Local)
+	for i, exp := range []time.Duration{
+		1 * time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		4 * time.Second,
+		4 * time.Second,
+	} {
+		n := i + 1
+		act := s.Delay(n, d)
+
+		if act != exp {
+			t.Errorf("delay #%d was %s, want %s", n, act, exp)
+		}
+	}
+}
+
+func TestBoundedExponentialPanic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for k = 0")
+		}
+	}()
+	backoff.BoundedExponential(1*time.Second, 2, 0)
+}
+
+func TestNewExponential(t *testing.T) {
+	for _, test := range []struct {
+		d time.Duration
+		m float64
+	}{
+		{-1 * time.Second, 2},
+		{1 * time.Second, -2},
+		{1 * time.Second, math.NaN()},
+	} {
+		if _, err := backoff.NewExponential(test.d, test.m); !errors.Is(err, backoff.ErrInvalid) {
+			t.Errorf("NewExponential(%s, %f): unexpected error: %v", test.d, test.m, err)
+		}
+	}
+	s, err := backoff.NewExponential(1*time.Second, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if act := s.Delay(3, time.Now()); act != 4*time.Second {
+		t.Errorf("delay was %s, want 4s", act)
+	}
+}
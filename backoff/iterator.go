@@ -0,0 +1,89 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff
+
+import "time"
+
+// An Iterator steps through the delays of a backoff [Strategy]. It suits
+// consumers that drive their own loops, such as select-based event loops,
+// while reusing the strategies and decorators of this package. Unlike a
+// strategy, an iterator is stateful, and hence not safe for concurrent use.
+// Use [Iterate] to create a new iterator.
+type Iterator struct {
+	strategy Strategy
+	clock    Clock
+	n        int       // number of delays handed out so far
+	start    time.Time // time of the first call to Next
+	done     bool      // whether the strategy signaled Exit
+}
+
+// Iterate creates a new [Iterator] over the delays of strategy. Time-based
+// decorators, such as [Timeout], measure the elapsed time from the first call
+// to [Iterator.Next], as supplied by clock. If clock is nil, the system clock
+// is used.
+func Iterate(strategy Strategy, clock Clock) *Iterator {
+	if clock == nil {
+		clock = ClockFunc(time.Now)
+	}
+	return &Iterator{
+		strategy: strategy,
+		clock:    clock,
+	}
+}
+
+// Next returns the next delay, or [Exit] once the strategy is exhausted. After
+// Exit has been returned, subsequent calls keep returning Exit until the
+// iterator is reset. For example:
+//
+//	it := backoff.Iterate(strategy, nil)
+//	for {
+//		d := it.Next()
+//		if d == backoff.Exit {
+//			return
+//		}
+//		select {
+//		case <-time.After(d):
+//			if poll() {
+//				it.Reset()
+//			}
+//		case <-quit:
+//			return
+//		}
+//	}
+func (it *Iterator) Next() time.Duration {
+	if it.done {
+		return Exit
+	}
+	if it.n == 0 {
+		it.start = it.clock.Time()
+	}
+	it.n++
+	d := it.strategy.Delay(it.n, it.start)
+	if d == Exit {
+		it.done = true
+	}
+	return d
+}
+
+// Reset rewinds the iterator, such that the next call to [Iterator.Next]
+// starts over with the first delay of the strategy. This is typically done
+// after an operation succeeded.
+func (it *Iterator) Reset() {
+	it.n = 0
+	it.start = time.Time{}
+	it.done = false
+}
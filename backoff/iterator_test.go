@@ -0,0 +1,63 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestIterator(t *testing.T) {
+	it := backoff.Iterate(backoff.Limit(backoff.Linear(1*time.Second, 1*time.Second), 3), nil)
+
+	for i := 0; i < 2; i++ {
+		for j, exp := range []time.Duration{
+			1 * time.Second,
+			2 * time.Second,
+			backoff.Exit,
+			backoff.Exit,
+		} {
+			if act := it.Next(); act != exp {
+				t.Errorf("#%d.%d: delay was %s, want %s", i, j, act, exp)
+			}
+		}
+		it.Reset()
+	}
+}
+
+func TestIterator_Clock(t *testing.T) {
+	now := time.Now()
+	clock := backoff.ClockFunc(func() time.Time { return now })
+	s := backoff.Timeout(backoff.Constant(1*time.Second), 1*time.Minute, clock)
+	it := backoff.Iterate(s, clock)
+
+	if act := it.Next(); act != 1*time.Second {
+		t.Errorf("delay was %s, want 1s", act)
+	}
+	now = now.Add(1 * time.Minute)
+	if act := it.Next(); act != backoff.Exit {
+		t.Errorf("delay was %s, want exit", act)
+	}
+
+	// the elapsed time is measured anew after a reset
+	it.Reset()
+	if act := it.Next(); act != 1*time.Second {
+		t.Errorf("delay was %s, want 1s", act)
+	}
+}
@@ -17,44 +17,218 @@
 package backoff
 
 import (
-	"fmt"
 	"time"
 )
 
 // Random returns a pseudo-random number in the half-open interval [0,1).
 type Random func() float64
 
+// scatter moves delay by up to spread percent in either direction, depending on
+// the random number r in [0,1).
+func scatter(delay time.Duration, spread float64, r float64) time.Duration {
+	w := float64(delay) * spread
+	return time.Duration(float64(delay) - w + (r * (2*w + 1)))
+}
+
 type jitter struct {
 	strategy Strategy // wrapped strategy
 	spread   float64  // spread factor
 	random   Random   // random number generator
 }
 
-func (j *jitter) Delay(n int, start time.Time) (delay time.Duration) {
-	delay = j.strategy.Delay(n, start)
+func (j *jitter) Delay(n int, start time.Time) time.Duration {
+	delay, _ := j.Explain(n, start)
+	return delay
+}
+
+func (j *jitter) Explain(
+	n int,
+	start time.Time,
+) (delay time.Duration, cause Cause) {
+	delay, cause = Explain(j.strategy, n, start)
 	if delay == Exit {
 		return
 	}
-	w := float64(delay) * j.spread
-	return time.Duration(float64(delay) - w + (j.random() * (2*w + 1)))
+	return scatter(delay, j.spread, j.random()), cause
 }
 
+func (j *jitter) Unwrap() Strategy { return j.strategy }
+
 // Jitter wraps a backoff [Strategy] to randomly spread produced delays around
 // in time. The spread factor determines the relative range in which delays are
 // scattered. It must fall in the half-open interval [0,1). For example, a
 // spread of 0.5 results in delays ranging between 50% above and 50% below the
 // values produced by the wrapped strategy. If spread = 0, no jitter will be
-// applied.
+// applied. Delays are scattered according to the distribution of random, which
+// is usually uniform. See [TruncNormal] and [TruncExp] for alternatives.
 func Jitter(strategy Strategy, spread float64, random Random) Strategy {
-	if spread < 0.0 || spread >= 1.0 {
-		panic(fmt.Sprintf("spread %f not in [0,1)", spread))
+	return must(NewJitter(strategy, spread, random))
+}
+
+// NewJitter works like [Jitter], but returns an error wrapping [ErrInvalid]
+// instead of panicking if spread is not in [0,1).
+func NewJitter(
+	strategy Strategy,
+	spread float64,
+	random Random,
+) (Strategy, error) {
+	if !(spread >= 0.0 && spread < 1.0) {
+		return nil, invalid("spread %f not in [0,1)", spread)
 	}
 	if spread == 0 {
-		return strategy
+		return strategy, nil
 	}
 	return &jitter{
 		strategy: strategy,
 		spread:   spread,
 		random:   random,
+	}, nil
+}
+
+// CappedJitter wraps a backoff [Strategy] to add random [Jitter] while
+// guaranteeing that the jittered delays never exceed max. Wrapping a capped
+// strategy in [Jitter] instead would allow delays to be scattered above the
+// cap. If max <= 0, no limit will be applied.
+func CappedJitter(
+	strategy Strategy,
+	spread float64,
+	max time.Duration,
+	random Random,
+) Strategy {
+	return Cap(Jitter(strategy, spread, random), max)
+}
+
+type scaledJitter struct {
+	strategy Strategy // wrapped strategy
+	spread   float64  // maximum spread factor
+	k        int      // attempt from which on the spread is at its maximum
+	random   Random   // random number generator
+}
+
+func (j *scaledJitter) Delay(n int, start time.Time) time.Duration {
+	delay, _ := j.Explain(n, start)
+	return delay
+}
+
+func (j *scaledJitter) Explain(
+	n int,
+	start time.Time,
+) (delay time.Duration, cause Cause) {
+	delay, cause = Explain(j.strategy, n, start)
+	if delay == Exit {
+		return
+	}
+	m := n
+	if m > j.k {
+		m = j.k
+	}
+	spread := j.spread * float64(m) / float64(j.k)
+	return scatter(delay, spread, j.random()), cause
+}
+
+func (j *scaledJitter) Unwrap() Strategy { return j.strategy }
+
+// ScaledJitter works like [Jitter], but the spread factor grows linearly with
+// the attempt count, reaching its maximum at the k-th attempt. This keeps early
+// retries timely, while later retries are maximally de-correlated. For example,
+// with spread = 0.5 and k = 5, the delay after the first attempt is jittered by
+// 10%, and delays after the fifth attempt and beyond are jittered by 50%. If
+// k <= 1, the result is equivalent to [Jitter].
+func ScaledJitter(
+	strategy Strategy,
+	spread float64,
+	k int,
+	random Random,
+) Strategy {
+	return must(NewScaledJitter(strategy, spread, k, random))
+}
+
+// NewScaledJitter works like [ScaledJitter], but returns an error wrapping
+// [ErrInvalid] instead of panicking if spread is not in [0,1).
+func NewScaledJitter(
+	strategy Strategy,
+	spread float64,
+	k int,
+	random Random,
+) (Strategy, error) {
+	if k <= 1 {
+		return NewJitter(strategy, spread, random)
+	}
+	if !(spread >= 0.0 && spread < 1.0) {
+		return nil, invalid("spread %f not in [0,1)", spread)
 	}
+	if spread == 0 {
+		return strategy, nil
+	}
+	return &scaledJitter{
+		strategy: strategy,
+		spread:   spread,
+		k:        k,
+		random:   random,
+	}, nil
+}
+
+type lateJitter struct {
+	strategy Strategy // wrapped strategy
+	k        int      // retry from which on jitter is applied
+	spread   float64  // spread factor
+	random   Random   // random number generator
+}
+
+func (j *lateJitter) Delay(n int, start time.Time) time.Duration {
+	delay, _ := j.Explain(n, start)
+	return delay
+}
+
+func (j *lateJitter) Explain(
+	n int,
+	start time.Time,
+) (delay time.Duration, cause Cause) {
+	delay, cause = Explain(j.strategy, n, start)
+	if delay == Exit || n < j.k {
+		return
+	}
+	return scatter(delay, j.spread, j.random()), cause
+}
+
+func (j *lateJitter) Unwrap() Strategy { return j.strategy }
+
+// JitterAfter works like [Jitter], but only applies jitter from the k-th retry
+// onward, i.e., to the delays following the k-th attempt and beyond. Earlier
+// delays are passed through unchanged, which keeps early retries deterministic
+// for tests and service level objectives, while still de-correlating long
+// tails. No random numbers are drawn for early retries. If k <= 1, the result
+// is equivalent to [Jitter].
+func JitterAfter(
+	strategy Strategy,
+	k int,
+	spread float64,
+	random Random,
+) Strategy {
+	return must(NewJitterAfter(strategy, k, spread, random))
+}
+
+// NewJitterAfter works like [JitterAfter], but returns an error wrapping
+// [ErrInvalid] instead of panicking if spread is not in [0,1).
+func NewJitterAfter(
+	strategy Strategy,
+	k int,
+	spread float64,
+	random Random,
+) (Strategy, error) {
+	if k <= 1 {
+		return NewJitter(strategy, spread, random)
+	}
+	if !(spread >= 0.0 && spread < 1.0) {
+		return nil, invalid("spread %f not in [0,1)", spread)
+	}
+	if spread == 0 {
+		return strategy, nil
+	}
+	return &lateJitter{
+		strategy: strategy,
+		k:        k,
+		spread:   spread,
+		random:   random,
+	}, nil
 }
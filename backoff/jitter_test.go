@@ -16,6 +16,8 @@
 package backoff_test
 
 import (
+	"errors"
+	"math"
 	"testing"
 	"time"
 
@@ -80,3 +82,132 @@ func TestJitterZero(t *testing.T) {
 		t.Errorf("delay was %s, want %s", act, exp)
 	}
 }
+
+func TestCappedJitterBelow(t *testing.T) {
+	s := backoff.CappedJitter(backoff.Constant(1*time.Second), 0.5, 2*time.Second, random(0.25))
+	act := s.Delay(1, time.Date(0, 0, 0, 0, 0, 0, 0, time.Local))
+
+	const exp = 750 * time.Millisecond
+
+	if act != exp {
+		t.Errorf("delay was %s, want %s", act, exp)
+	}
+}
+
+func TestCappedJitterAbove(t *testing.T) {
+	s := backoff.CappedJitter(backoff.Constant(1*time.Second), 0.5, 1*time.Second, random(0.75))
+	act := s.Delay(1, time.Date(0, 0, 0, 0, 0, 0, 0, time.Local))
+
+	const exp = 1 * time.Second
+
+	if act != exp {
+		t.Errorf("delay was %s, want %s", act, exp)
+	}
+}
+
+func TestScaledJitter(t *testing.T) {
+	s := backoff.ScaledJitter(backoff.Constant(1*time.Second), 0.5, 5, random(0))
+
+	d := time.Date(0, 0, 0, 0, 0, 0, 0, time.Local)
+	for i, exp := range []time.Duration{
+		900 * time.Millisecond,
+		800 * time.Millisecond,
+		700 * time.Millisecond,
+		600 * time.Millisecond,
+		500 * time.Millisecond,
+		500 * time.Millisecond,
+	} {
+		n := i + 1
+		act := s.Delay(n, d)
+
+		if act != exp {
+			t.Errorf("delay #%d was %s, want %s", n, act, exp)
+		}
+	}
+}
+
+func TestScaledJitterExit(t *testing.T) {
+	s := backoff.ScaledJitter(backoff.Once, 0.5, 5, random(0.5))
+	act := s.Delay(1, time.Date(0, 0, 0, 0, 0, 0, 0, time.Local))
+
+	exp := backoff.Exit
+
+	if act != exp {
+		t.Errorf("delay was %s, want %s", act, exp)
+	}
+}
+
+func TestJitterAfter(t *testing.T) {
+	s := backoff.JitterAfter(backoff.Constant(1*time.Second), 3, 0.5, random(0))
+
+	d := time.Date(0, 0, 0, 0, 0, 0, 0, time.Local)
+	for i, exp := range []time.Duration{
+		1 * time.Second,
+		1 * time.Second,
+		500 * time.Millisecond,
+		500 * time.Millisecond,
+	} {
+		n := i + 1
+		act := s.Delay(n, d)
+
+		if act != exp {
+			t.Errorf("delay #%d was %s, want %s", n, act, exp)
+		}
+	}
+}
+
+func TestNewJitter(t *testing.T) {
+	base := backoff.Constant(1 * time.Second)
+	for _, spread := range []float64{-0.1, 1, math.NaN()} {
+		if _, err := backoff.NewJitter(base, spread, random(0)); !errors.Is(err, backoff.ErrInvalid) {
+			t.Errorf("NewJitter(%f): unexpected error: %v", spread, err)
+		}
+	}
+	s, err := backoff.NewJitter(base, 0.5, random(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if act := s.Delay(1, time.Now()); act != 500*time.Millisecond {
+		t.Errorf("delay was %s, want 500ms", act)
+	}
+}
+
+func TestNewScaledJitter(t *testing.T) {
+	base := backoff.Constant(1 * time.Second)
+	for _, k := range []int{1, 5} {
+		for _, spread := range []float64{-0.1, 1, math.NaN()} {
+			_, err := backoff.NewScaledJitter(base, spread, k, random(0))
+			if !errors.Is(err, backoff.ErrInvalid) {
+				t.Errorf("NewScaledJitter(%f, %d): unexpected error: %v",
+					spread, k, err)
+			}
+		}
+	}
+	s, err := backoff.NewScaledJitter(base, 0.5, 5, random(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if act := s.Delay(5, time.Now()); act != 500*time.Millisecond {
+		t.Errorf("delay was %s, want 500ms", act)
+	}
+}
+
+func TestNewJitterAfter(t *testing.T) {
+	base := backoff.Constant(1 * time.Second)
+	for _, k := range []int{1, 5} {
+		for _, spread := range []float64{-0.1, 1, math.NaN()} {
+			_, err := backoff.NewJitterAfter(base, k, spread, random(0))
+			if !errors.Is(err, backoff.ErrInvalid) {
+				t.Errorf("NewJitterAfter(%d, %f): unexpected error: %v",
+					k, spread, err)
+			}
+		}
+	}
+	s, err := backoff.NewJitterAfter(base, 5, 0.5, random(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if act := s.Delay(5, time.Now()); act != 500*time.Millisecond {
+		t.Errorf("delay was %s, want 500ms", act)
+	}
+}
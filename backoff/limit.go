@@ -24,13 +24,21 @@ type limit struct {
 }
 
 func (lim *limit) Delay(n int, start time.Time) time.Duration {
+	delay, _ := lim.Explain(n, start)
+	return delay
+}
+
+func (lim *limit) Explain(n int, start time.Time) (time.Duration, Cause) {
 	if n >= lim.n {
-		return Exit
+		return Exit, CauseLimit
 	}
-	return lim.strategy.Delay(n, start)
+	return Explain(lim.strategy, n, start)
 }
 
-// Limit wraps a backoff [Strategy] to end the retry cycle after n attempts. If
+func (lim *limit) Unwrap() Strategy { return lim.strategy }
+
+// Limit wraps a backoff [Strategy] to end the retry cycle after n attempts. The
+// count includes the initial attempt, so n = 3 allows for two retries. If
 // n < 1, no limit will be applied.
 func Limit(strategy Strategy, n int) Strategy {
 	if n < 1 {
@@ -41,3 +49,13 @@ func Limit(strategy Strategy, n int) Strategy {
 		n:        n,
 	}
 }
+
+// LimitRetries wraps a backoff [Strategy] to end the retry cycle after n
+// retries. Unlike [Limit], the count excludes the initial attempt, so n = 3
+// allows for four attempts in total. If n < 0, no limit will be applied.
+func LimitRetries(strategy Strategy, n int) Strategy {
+	if n < 0 {
+		return strategy
+	}
+	return Limit(strategy, n+1)
+}
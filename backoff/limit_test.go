@@ -55,3 +55,32 @@ func TestLimitZero(t *testing.T) {
 		t.Errorf("delay was %s, want %s", act, exp)
 	}
 }
+
+func TestLimitRetries(t *testing.T) {
+	s := backoff.LimitRetries(backoff.Constant(1*time.Second), 2)
+	d := time.Date(0, 0, 0, 0, 0, 0, 0, time.Local)
+
+	for i, exp := range []time.Duration{
+		1 * time.Second,
+		1 * time.Second,
+		backoff.Exit,
+	} {
+		n := i + 1
+		act := s.Delay(n, d)
+
+		if act != exp {
+			t.Errorf("delay #%d was %s, want %s", n, act, exp)
+		}
+	}
+}
+
+func TestLimitRetriesZero(t *testing.T) {
+	s := backoff.LimitRetries(backoff.Constant(1*time.Second), 0)
+	act := s.Delay(1, time.Date(0, 0, 0, 0, 0, 0, 0, time.Local))
+
+	exp := backoff.Exit
+
+	if act != exp {
+		t.Errorf("delay was %s, want %s", act, exp)
+	}
+}
@@ -16,10 +16,7 @@
 
 package backoff
 
-import (
-	"fmt"
-	"time"
-)
+import "time"
 
 type linear struct {
 	d time.Duration // initial delay
@@ -39,15 +36,21 @@ func (lin *linear) Delay(n int, start time.Time) time.Duration {
 // the delay shrinks to 0 and then stops decreasing. The function panics if
 // d is negative.
 func Linear(d time.Duration, k time.Duration) Strategy {
+	return must(NewLinear(d, k))
+}
+
+// NewLinear works like [Linear], but returns an error wrapping [ErrInvalid]
+// instead of panicking if d is negative.
+func NewLinear(d time.Duration, k time.Duration) (Strategy, error) {
 	switch {
 	case d < 0:
-		panic(fmt.Sprintf("d = %s, must be >= 0", d))
+		return nil, invalid("d = %s, must be >= 0", d)
 	case k == 0:
-		return Constant(d)
+		return NewConstant(d)
 	default:
 		return &linear{
 			d: d,
 			k: k,
-		}
+		}, nil
 	}
 }
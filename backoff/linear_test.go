@@ -17,6 +17,7 @@
 package backoff_test
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -60,3 +61,16 @@ func TestLinearNegative(t *testing.T) {
 		}
 	}
 }
+
+func TestNewLinear(t *testing.T) {
+	if _, err := backoff.NewLinear(-1*time.Second, 0); !errors.Is(err, backoff.ErrInvalid) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	s, err := backoff.NewLinear(1*time.Second, 1*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if act := s.Delay(2, time.Now()); act != 2*time.Second {
+		t.Errorf("delay was %s, want 2s", act)
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff
+
+import (
+	"fmt"
+	"time"
+)
+
+type minAttempts struct {
+	strategy Strategy // wrapped strategy
+	k        int      // minimum number of attempts
+}
+
+func (m *minAttempts) Delay(n int, start time.Time) time.Duration {
+	delay, _ := m.Explain(n, start)
+	return delay
+}
+
+func (m *minAttempts) Explain(n int, start time.Time) (time.Duration, Cause) {
+	delay, cause := Explain(m.strategy, n, start)
+	if delay == Exit && n < m.k {
+		return 0, CauseUnknown
+	}
+	return delay, cause
+}
+
+func (m *minAttempts) Unwrap() Strategy { return m.strategy }
+
+// MinAttempts wraps a backoff [Strategy] to guarantee at least k attempts per
+// retry cycle, including the initial attempt. If the wrapped strategy ends the
+// cycle earlier, e.g. because a [Timeout] elapsed during a slow first attempt,
+// the remaining attempts follow without delay. This suits operations whose
+// policy is to never give up with fewer than k tries. The function panics if
+// k < 1.
+func MinAttempts(strategy Strategy, k int) Strategy {
+	if k < 1 {
+		panic(fmt.Sprintf("k = %d, must be >= 1", k))
+	}
+	return &minAttempts{
+		strategy: strategy,
+		k:        k,
+	}
+}
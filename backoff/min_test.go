@@ -0,0 +1,49 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestMinAttempts(t *testing.T) {
+	s := backoff.MinAttempts(backoff.Limit(backoff.Constant(1*time.Second), 2), 4)
+
+	for i, exp := range []time.Duration{
+		1 * time.Second,
+		0,
+		0,
+		backoff.Exit,
+	} {
+		n := i + 1
+		if act := s.Delay(n, time.Now()); act != exp {
+			t.Errorf("delay for n = %d was %s, want %s", n, act, exp)
+		}
+	}
+}
+
+func TestMinAttemptsPanic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	backoff.MinAttempts(backoff.Constant(0), 0)
+}
@@ -0,0 +1,115 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff
+
+import (
+	"fmt"
+	"math"
+)
+
+// TruncNormal returns a [Random] whose numbers follow a normal distribution
+// centered at 0.5 with standard deviation sigma, truncated to the half-open
+// interval [0,1). Uniformly distributed numbers are drawn from source. Passing
+// the result to [Jitter] scatters delays in a bell shape around the values
+// produced by the wrapped strategy. Numbers outside of [0,1) are rejected and
+// drawn anew, up to a fixed number of times. If all of them are rejected, as
+// with a constant source or a very large sigma, a number drawn from source is
+// returned as is, since the distribution approaches the uniform one as sigma
+// grows. The function panics if sigma <= 0.
+func TruncNormal(source Random, sigma float64) Random {
+	if sigma <= 0 {
+		panic(fmt.Sprintf("sigma = %f, must be > 0", sigma))
+	}
+	return func() float64 {
+		for i := 0; i < rejections; i++ {
+			// Box-Muller transform
+			u1, u2 := 1-source(), source()
+			z := math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2)
+			if r := 0.5 + sigma*z; r >= 0 && r < 1 {
+				return r
+			}
+		}
+		return source()
+	}
+}
+
+// rejections is the maximum number of draws in rejection sampling. For
+// sigma <= 2, [TruncNormal] accepts about one in five draws of a fair source or
+// more, so that the limit is exceeded with negligible probability.
+const rejections = 100
+
+// TruncExp returns a [Random] whose numbers follow an exponential distribution
+// with rate lambda, truncated to the half-open interval [0,1). Uniformly
+// distributed numbers are drawn from source. Passing the result to [Jitter]
+// favors delays at the lower end of the jitter range, with larger values of
+// lambda shifting more weight towards it. The function panics if lambda <= 0.
+func TruncExp(source Random, lambda float64) Random {
+	if lambda <= 0 {
+		panic(fmt.Sprintf("lambda = %f, must be > 0", lambda))
+	}
+	m := 1 - math.Exp(-lambda) // probability mass in [0,1)
+	return func() float64 {
+		// inverse transform sampling
+		r := -math.Log(1-source()*m) / lambda
+		if r >= 1 {
+			// guard against rounding errors
+			r = math.Nextafter(1, 0)
+		}
+		return r
+	}
+}
+
+// golden is the fractional part of the golden ratio.
+const golden = 0.6180339887498949
+
+// Golden returns a [Random] generating the low-discrepancy sequence obtained by
+// repeatedly adding the golden ratio to offset, modulo 1. Successive numbers
+// are spread more evenly over [0,1) than uniformly distributed ones, which
+// keeps consecutive jittered delays of the same retry cycle from clustering.
+// The offset is usually drawn at random, such that different cycles follow
+// different sequences. The result is stateful and must not be shared among
+// concurrent goroutines.
+func Golden(offset float64) Random {
+	x := offset - math.Floor(offset)
+	return func() float64 {
+		if x += golden; x >= 1 {
+			x--
+		}
+		return x
+	}
+}
+
+// Halton returns a [Random] generating the Halton sequence in the given base,
+// which is a low-discrepancy sequence like [Golden]. The sequence starts with
+// 1/base. The result is stateful and must not be shared among concurrent
+// goroutines. The function panics if base < 2.
+func Halton(base int) Random {
+	if base < 2 {
+		panic(fmt.Sprintf("base = %d, must be >= 2", base))
+	}
+	k := 0
+	return func() float64 {
+		k++
+		// radical inverse of k
+		r, f := 0.0, 1.0
+		for i := k; i > 0; i /= base {
+			f /= float64(base)
+			r += f * float64(i%base)
+		}
+		return r
+	}
+}
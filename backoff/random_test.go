@@ -0,0 +1,104 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+// sample draws n numbers from random, checks that they fall in [0,1), and
+// returns their mean.
+func sample(t *testing.T, random backoff.Random, n int) float64 {
+	sum := 0.0
+	for i := 0; i < n; i++ {
+		r := random()
+		if r < 0 || r >= 1 {
+			t.Fatalf("random number %f not in [0,1)", r)
+		}
+		sum += r
+	}
+	return sum / float64(n)
+}
+
+func TestTruncNormal(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	mean := sample(t, backoff.TruncNormal(src.Float64, 0.2), 10000)
+
+	if math.Abs(mean-0.5) > 0.01 {
+		t.Errorf("mean was %f, want 0.5", mean)
+	}
+}
+
+func TestTruncNormal_Constant(t *testing.T) {
+	for _, c := range []float64{0, 0.25, 0.999} {
+		src := func() float64 { return c }
+		sample(t, backoff.TruncNormal(src, 1), 10)
+	}
+}
+
+func TestTruncExp(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	mean := sample(t, backoff.TruncExp(src.Float64, 3), 10000)
+
+	// mean of the exponential distribution truncated to [0,1)
+	exp := 1/3.0 - math.Exp(-3)/(1-math.Exp(-3))
+
+	if math.Abs(mean-exp) > 0.01 {
+		t.Errorf("mean was %f, want %f", mean, exp)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	r := backoff.Golden(1.25)
+
+	x := 0.25
+	for i := 0; i < 100; i++ {
+		if x += 0.6180339887498949; x >= 1 {
+			x--
+		}
+		act := r()
+		if act < 0 || act >= 1 {
+			t.Fatalf("#%d: %f not in [0,1)", i, act)
+		}
+		if math.Abs(act-x) > 1e-9 {
+			t.Errorf("#%d: number was %f, want %f", i, act, x)
+		}
+	}
+}
+
+func TestHalton(t *testing.T) {
+	r := backoff.Halton(2)
+
+	for i, exp := range []float64{0.5, 0.25, 0.75, 0.125, 0.625, 0.375, 0.875} {
+		if act := r(); act != exp {
+			t.Errorf("#%d: number was %f, want %f", i, act, exp)
+		}
+	}
+}
+
+func TestHaltonPanic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for base = 1")
+		}
+	}()
+	backoff.Halton(1)
+}
@@ -0,0 +1,51 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff
+
+import (
+	"fmt"
+	"time"
+)
+
+// A Redelivery describes when a message should be redelivered by a message
+// broker after a failed delivery.
+type Redelivery struct {
+	Delay      time.Duration // delay until the next delivery
+	At         time.Time     // time at which the message becomes visible again
+	DeadLetter bool          // whether the message should be dead-lettered
+}
+
+// Redeliver computes the [Redelivery] of a message that has been delivered
+// count times since the first delivery, with the most recent delivery failing
+// at time now. The count starts at 1 and corresponds to the attempt count of
+// an in-process retry cycle that started at first, which allows broker-based
+// retry topologies to reuse the exact same strategies. The message should be
+// dead-lettered if the strategy signals the end of the cycle, in which case
+// only the DeadLetter flag is set. The function panics if count < 1.
+func Redeliver(strategy Strategy, count int, first, now time.Time) Redelivery {
+	if count < 1 {
+		panic(fmt.Sprintf("count = %d, must be >= 1", count))
+	}
+	delay := strategy.Delay(count, first)
+	if delay == Exit {
+		return Redelivery{DeadLetter: true}
+	}
+	return Redelivery{
+		Delay: delay,
+		At:    now.Add(delay),
+	}
+}
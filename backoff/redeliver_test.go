@@ -0,0 +1,61 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestRedeliver(t *testing.T) {
+	first := time.Date(0, 0, 0, 0, 0, 0, 0, time.Local)
+	now := first.Add(5 * time.Second)
+
+	s := backoff.Limit(backoff.Linear(1*time.Second, 1*time.Second), 3)
+	act := backoff.Redeliver(s, 2, first, now)
+
+	exp := backoff.Redelivery{
+		Delay: 2 * time.Second,
+		At:    now.Add(2 * time.Second),
+	}
+
+	if act != exp {
+		t.Errorf("redelivery was %+v, want %+v", act, exp)
+	}
+}
+
+func TestRedeliverDeadLetter(t *testing.T) {
+	first := time.Date(0, 0, 0, 0, 0, 0, 0, time.Local)
+
+	s := backoff.Limit(backoff.Constant(1*time.Second), 3)
+	act := backoff.Redeliver(s, 3, first, first)
+
+	if !act.DeadLetter {
+		t.Errorf("redelivery was %+v, want dead letter", act)
+	}
+}
+
+func TestRedeliverPanic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for count = 0")
+		}
+	}()
+	backoff.Redeliver(backoff.Constant(0), 0, time.Time{}, time.Time{})
+}
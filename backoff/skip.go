@@ -0,0 +1,46 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff
+
+import "time"
+
+type skipFirst struct {
+	strategy Strategy // wrapped strategy
+}
+
+func (s *skipFirst) Delay(n int, start time.Time) time.Duration {
+	delay, _ := s.Explain(n, start)
+	return delay
+}
+
+func (s *skipFirst) Explain(n int, start time.Time) (time.Duration, Cause) {
+	if n <= 1 {
+		return 0, CauseUnknown
+	}
+	return Explain(s.strategy, n-1, start)
+}
+
+func (s *skipFirst) Unwrap() Strategy { return s.strategy }
+
+// SkipFirst wraps a backoff [Strategy] such that the first retry happens
+// immediately, and the wrapped strategy only applies from the second retry
+// onward. In other words, the delay after the n-th attempt equals the delay
+// the wrapped strategy produces for n-1. This implements the common pattern of
+// one instant retry followed by regular backoff.
+func SkipFirst(strategy Strategy) Strategy {
+	return &skipFirst{strategy: strategy}
+}
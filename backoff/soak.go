@@ -0,0 +1,138 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// A FailureModel reports whether the n-th attempt of a simulated retry cycle
+// fails. See [Soak].
+type FailureModel func(n int) bool
+
+// Bernoulli returns a [FailureModel] in which each attempt fails independently
+// with probability p, based on the numbers drawn from random. The function
+// panics if p is not in [0,1].
+func Bernoulli(p float64, random Random) FailureModel {
+	if p < 0 || p > 1 {
+		panic(fmt.Sprintf("p %f not in [0,1]", p))
+	}
+	return func(int) bool { return random() < p }
+}
+
+// MaxSoakAttempts is the maximum number of attempts simulated per cycle. Cycles
+// that reach it are counted as failed, which guards against strategies that
+// never signal [Exit].
+const MaxSoakAttempts = 10000
+
+// A SoakReport summarizes the outcome of a [Soak] simulation.
+type SoakReport struct {
+	Cycles    int             // number of simulated cycles
+	Succeeded int             // number of cycles that succeeded
+	attempts  []int           // attempts per cycle, sorted
+	durations []time.Duration // duration per cycle, sorted
+}
+
+// SuccessRate returns the fraction of cycles that succeeded.
+func (r *SoakReport) SuccessRate() float64 {
+	if r.Cycles == 0 {
+		return 0
+	}
+	return float64(r.Succeeded) / float64(r.Cycles)
+}
+
+// Attempts returns the p-th percentile of the number of attempts per cycle,
+// where p is in [0,100].
+func (r *SoakReport) Attempts(p float64) int {
+	if len(r.attempts) == 0 {
+		return 0
+	}
+	return r.attempts[rank(p, len(r.attempts))]
+}
+
+// Duration returns the p-th percentile of the cycle durations, where p is in
+// [0,100].
+func (r *SoakReport) Duration(p float64) time.Duration {
+	if len(r.durations) == 0 {
+		return 0
+	}
+	return r.durations[rank(p, len(r.durations))]
+}
+
+// rank returns the index of the p-th percentile in a sorted sample of size n,
+// using the nearest-rank method.
+func rank(p float64, n int) int {
+	i := int(math.Ceil(p/100*float64(n))) - 1
+	if i < 0 {
+		return 0
+	}
+	if i >= n {
+		return n - 1
+	}
+	return i
+}
+
+// Soak simulates the given number of retry cycles following strategy, where
+// attempts fail according to model, and reports the distribution of attempt
+// counts and cycle durations. Attempts are assumed to take no time, and delays
+// elapse instantly, so even long policies can be simulated quickly. This
+// allows for tuning policies against service level objectives before they are
+// deployed. Time-based strategies observe the simulated time as long as they
+// measure it using the system clock, since each cycle is backdated by the
+// simulated time elapsed. The function panics if iterations < 1.
+func Soak(strategy Strategy, model FailureModel, iterations int) *SoakReport {
+	if iterations < 1 {
+		panic(fmt.Sprintf("iterations = %d, must be >= 1", iterations))
+	}
+	r := &SoakReport{
+		Cycles:    iterations,
+		attempts:  make([]int, 0, iterations),
+		durations: make([]time.Duration, 0, iterations),
+	}
+	for i := 0; i < iterations; i++ {
+		var elapsed time.Duration
+		n := 1
+		for ; n <= MaxSoakAttempts; n++ {
+			if !model(n) {
+				r.Succeeded++
+				break
+			}
+			if n == MaxSoakAttempts {
+				break
+			}
+			// backdate the start by the simulated time
+			delay := strategy.Delay(n, time.Now().Add(-elapsed))
+			if delay == Exit {
+				break
+			}
+			elapsed += delay
+		}
+		if n > MaxSoakAttempts {
+			n = MaxSoakAttempts
+		}
+		r.attempts = append(r.attempts, n)
+		r.durations = append(r.durations, elapsed)
+	}
+	sort.Ints(r.attempts)
+	sort.Slice(r.durations, func(i, j int) bool {
+		return r.durations[i] < r.durations[j]
+	})
+	return r
+}
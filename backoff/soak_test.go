@@ -0,0 +1,86 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestSoak(t *testing.T) {
+	s := backoff.Limit(backoff.Constant(1*time.Second), 3)
+
+	// the first attempt of every other cycle succeeds, the others never do
+	i := 0
+	model := func(n int) bool {
+		if n == 1 {
+			i++
+		}
+		return i%2 == 0
+	}
+
+	r := backoff.Soak(s, model, 10)
+
+	if r.Cycles != 10 || r.Succeeded != 5 {
+		t.Errorf("got %d of %d cycles succeeded", r.Succeeded, r.Cycles)
+	}
+	if rate := r.SuccessRate(); rate != 0.5 {
+		t.Errorf("success rate was %f, want 0.5", rate)
+	}
+	if act := r.Attempts(50); act != 1 {
+		t.Errorf("p50 attempts was %d, want 1", act)
+	}
+	if act := r.Attempts(90); act != 3 {
+		t.Errorf("p90 attempts was %d, want 3", act)
+	}
+	if act := r.Duration(100); act != 2*time.Second {
+		t.Errorf("max duration was %s, want 2s", act)
+	}
+}
+
+func TestSoakTimeout(t *testing.T) {
+	s := backoff.Timeout(backoff.Constant(1*time.Minute), 5*time.Minute, backoff.ClockFunc(time.Now))
+
+	r := backoff.Soak(s, func(int) bool { return true }, 1)
+
+	if act := r.Attempts(100); act != 6 {
+		t.Errorf("attempts was %d, want 6", act)
+	}
+}
+
+func TestSoakBernoulli(t *testing.T) {
+	rd := rand.New(rand.NewSource(1))
+	s := backoff.Limit(backoff.Constant(0), 2)
+
+	r := backoff.Soak(s, backoff.Bernoulli(0.5, rd.Float64), 10000)
+
+	// P(success) = 1 - 0.5^2
+	if rate := r.SuccessRate(); rate < 0.73 || rate > 0.77 {
+		t.Errorf("success rate was %f, want about 0.75", rate)
+	}
+}
+
+func TestSoakUnbounded(t *testing.T) {
+	r := backoff.Soak(backoff.Constant(0), func(int) bool { return true }, 1)
+
+	if r.Succeeded != 0 || r.Attempts(100) != backoff.MaxSoakAttempts {
+		t.Errorf("unexpected report: %+v", r)
+	}
+}
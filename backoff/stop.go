@@ -0,0 +1,72 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff
+
+import "time"
+
+type stopIf struct {
+	strategy Strategy    // wrapped strategy
+	cond     func() bool // signals the end of the cycle
+}
+
+func (s *stopIf) Delay(n int, start time.Time) time.Duration {
+	delay, _ := s.Explain(n, start)
+	return delay
+}
+
+func (s *stopIf) Explain(n int, start time.Time) (time.Duration, Cause) {
+	if s.cond() {
+		return Exit, CauseSignal
+	}
+	return Explain(s.strategy, n, start)
+}
+
+func (s *stopIf) Unwrap() Strategy { return s.strategy }
+
+// StopIf wraps a backoff [Strategy] to end the retry cycle as soon as cond
+// returns true. The condition is evaluated each time a delay is computed, i.e.
+// after each failed attempt. This allows controllers outside of the context
+// tree, such as feature flags, circuit breakers or shutdown hooks, to halt
+// retry cycles. The condition must be safe for concurrent use if the strategy
+// is shared. If cond is nil, the strategy is returned as is.
+func StopIf(strategy Strategy, cond func() bool) Strategy {
+	if cond == nil {
+		return strategy
+	}
+	return &stopIf{
+		strategy: strategy,
+		cond:     cond,
+	}
+}
+
+// StopOn works like [StopIf], but ends the retry cycle once signal is closed
+// or receives a value. Closing the channel halts all cycles that observe it,
+// whereas each value sent halts at most one cycle. If signal is nil, the
+// strategy is returned as is.
+func StopOn(strategy Strategy, signal <-chan struct{}) Strategy {
+	if signal == nil {
+		return strategy
+	}
+	return StopIf(strategy, func() bool {
+		select {
+		case <-signal:
+			return true
+		default:
+			return false
+		}
+	})
+}
@@ -0,0 +1,68 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestStopIf(t *testing.T) {
+	stop := false
+	s := backoff.StopIf(backoff.Constant(1*time.Second), func() bool { return stop })
+
+	if act := s.Delay(1, time.Now()); act != 1*time.Second {
+		t.Errorf("delay was %s, want 1s", act)
+	}
+	stop = true
+	if act := s.Delay(2, time.Now()); act != backoff.Exit {
+		t.Errorf("delay was %s, want exit", act)
+	}
+}
+
+func TestStopOn(t *testing.T) {
+	signal := make(chan struct{}, 1)
+	s := backoff.StopOn(backoff.Constant(1*time.Second), signal)
+
+	if act := s.Delay(1, time.Now()); act != 1*time.Second {
+		t.Errorf("delay was %s, want 1s", act)
+	}
+	signal <- struct{}{}
+	if act := s.Delay(2, time.Now()); act != backoff.Exit {
+		t.Errorf("delay was %s, want exit", act)
+	}
+	// the value was consumed
+	if act := s.Delay(3, time.Now()); act != 1*time.Second {
+		t.Errorf("delay was %s, want 1s", act)
+	}
+	close(signal)
+	if act := s.Delay(4, time.Now()); act != backoff.Exit {
+		t.Errorf("delay was %s, want exit", act)
+	}
+}
+
+func TestStopIf_Nil(t *testing.T) {
+	base := backoff.Constant(1 * time.Second)
+	if s := backoff.StopIf(base, nil); s != base {
+		t.Error("expected the strategy to be returned as is")
+	}
+	if s := backoff.StopOn(base, nil); s != base {
+		t.Error("expected the strategy to be returned as is")
+	}
+}
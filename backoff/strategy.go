@@ -19,14 +19,39 @@
 // In particular, the package implements [Constant], [Linear] and [Exponential]
 // backoff strategies as well as some decorators to adjust their behavior. These
 // include setting a [Timeout], a delay [Cap], an attempt [Limit], or adding
-// random [Jitter].
+// random [Jitter]. A [Builder] composes these decorators in a canonical order,
+// whereas [Compose] applies them in the order given.
 package backoff
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 // Exit is returned by a backoff Strategy to signal the end of a retry cycle.
 var Exit time.Duration = -1
 
+// ErrInvalid is matched through errors.Is by the errors that constructors such
+// as [NewExponential] return for invalid arguments. Their panicking
+// counterparts, such as [Exponential], suit arguments fixed at compile time,
+// whereas the former suit arguments taken from user input.
+var ErrInvalid = errors.New("backoff: invalid argument")
+
+// invalid returns an error that wraps [ErrInvalid], describing the violated
+// constraint in the manner of fmt.Sprintf.
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalid, fmt.Sprintf(format, args...))
+}
+
+// must returns s, or panics if err is not nil.
+func must(s Strategy, err error) Strategy {
+	if err != nil {
+		panic(err.Error())
+	}
+	return s
+}
+
 // Strategy determines the delay between consecutive retries in a backoff
 // scenario. Implementations of this interface should be stateless because they
 // might be used in multiple concurrent goroutines.
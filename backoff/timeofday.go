@@ -0,0 +1,118 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// day is the length of a day on the wall clock.
+const day = 24 * time.Hour
+
+// A Window adjusts the delays produced during a daily time window. See
+// [TimeOfDay].
+type Window struct {
+	From   time.Duration // start of the window as offset from midnight
+	To     time.Duration // end of the window (exclusive); may wrap midnight
+	Factor float64       // multiplier applied to delays; 0 means 1
+	Cap    time.Duration // maximum delay after scaling; 0 means no cap
+}
+
+// contains reports whether the time of day t falls into the window.
+func (w Window) contains(t time.Duration) bool {
+	if w.From <= w.To {
+		return t >= w.From && t < w.To
+	}
+	return t >= w.From || t < w.To
+}
+
+// apply adjusts delay according to the window.
+func (w Window) apply(delay time.Duration) time.Duration {
+	if w.Factor > 0 {
+		f := float64(delay) * w.Factor
+		if f >= math.MaxInt64 {
+			delay = math.MaxInt64
+		} else {
+			delay = time.Duration(f)
+		}
+	}
+	if w.Cap > 0 && delay > w.Cap {
+		delay = w.Cap
+	}
+	return delay
+}
+
+type timeOfDay struct {
+	strategy Strategy // wrapped strategy
+	clock    Clock    // determines the time of day
+	schedule []Window // daily windows
+}
+
+func (s *timeOfDay) Delay(n int, start time.Time) time.Duration {
+	delay, _ := s.Explain(n, start)
+	return delay
+}
+
+func (s *timeOfDay) Explain(n int, start time.Time) (time.Duration, Cause) {
+	delay, cause := Explain(s.strategy, n, start)
+	if delay == Exit {
+		return Exit, cause
+	}
+	t := s.clock.Time()
+	h, m, sec := t.Clock()
+	now := time.Duration(h)*time.Hour +
+		time.Duration(m)*time.Minute +
+		time.Duration(sec)*time.Second +
+		time.Duration(t.Nanosecond())
+	for _, w := range s.schedule {
+		if w.contains(now) {
+			return w.apply(delay), cause
+		}
+	}
+	return delay, cause
+}
+
+func (s *timeOfDay) Unwrap() Strategy { return s.strategy }
+
+// TimeOfDay wraps a backoff [Strategy] to adjust its delays depending on the
+// time of day, as read from clock in its own location. This allows for
+// retrying more aggressively off-peak, and more conservatively during business
+// peaks. The first [Window] of the schedule that contains the current time of
+// day applies; delays outside of all windows remain unchanged. The function
+// panics if a window bound is not in [0,24h), or a factor is negative.
+func TimeOfDay(strategy Strategy, clock Clock, schedule ...Window) Strategy {
+	for _, w := range schedule {
+		switch {
+		case w.From < 0 || w.From >= day:
+			panic(fmt.Sprintf("from %s not in [0,24h)", w.From))
+		case w.To < 0 || w.To >= day:
+			panic(fmt.Sprintf("to %s not in [0,24h)", w.To))
+		case w.Factor < 0:
+			panic(fmt.Sprintf("factor = %f, must be >= 0", w.Factor))
+		}
+	}
+	if len(schedule) == 0 {
+		return strategy
+	}
+	return &timeOfDay{
+		strategy: strategy,
+		clock:    clock,
+		schedule: append([]Window(nil), schedule...),
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestTimeOfDay(t *testing.T) {
+	var now time.Time
+	clock := backoff.ClockFunc(func() time.Time { return now })
+
+	s := backoff.TimeOfDay(backoff.Constant(10*time.Second), clock,
+		backoff.Window{From: 9 * time.Hour, To: 17 * time.Hour, Factor: 3, Cap: 20 * time.Second},
+		backoff.Window{From: 22 * time.Hour, To: 6 * time.Hour, Factor: 0.5},
+	)
+
+	tests := []struct {
+		hour int
+		exp  time.Duration
+	}{
+		{8, 10 * time.Second},
+		{9, 20 * time.Second},
+		{16, 20 * time.Second},
+		{17, 10 * time.Second},
+		{23, 5 * time.Second},
+		{2, 5 * time.Second},
+		{6, 10 * time.Second},
+	}
+
+	for _, test := range tests {
+		now = time.Date(2024, 1, 1, test.hour, 30, 0, 0, time.UTC)
+		if act := s.Delay(1, now); act != test.exp {
+			t.Errorf("delay at %d:30 was %s, want %s", test.hour, act, test.exp)
+		}
+	}
+}
+
+func TestTimeOfDay_Exit(t *testing.T) {
+	clock := backoff.ClockFunc(time.Now)
+	s := backoff.TimeOfDay(backoff.Limit(backoff.Constant(1*time.Second), 1), clock,
+		backoff.Window{From: 0, To: 0, Factor: 2},
+	)
+	if act := s.Delay(1, time.Now()); act != backoff.Exit {
+		t.Errorf("delay was %s, want exit", act)
+	}
+}
+
+func TestTimeOfDay_Panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	backoff.TimeOfDay(backoff.Constant(0), backoff.ClockFunc(time.Now),
+		backoff.Window{From: 25 * time.Hour},
+	)
+}
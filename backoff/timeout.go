@@ -29,6 +29,17 @@ type Clock interface {
 
 func (f ClockFunc) Time() time.Time { return f() }
 
+// A Timer is a [Clock] that also controls when delays elapse. Retry cycles
+// whose clock implements this interface wait by means of the timer rather than
+// the system clock. This enables fully deterministic simulations of retry
+// cycles, in which virtual time advances as fast as the simulation demands.
+type Timer interface {
+	Clock
+	// After waits for the duration d to elapse and then sends the current time
+	// on the returned channel.
+	After(d time.Duration) <-chan time.Time
+}
+
 type timeout struct {
 	strategy Strategy      // wrapped strategy
 	clock    Clock         // determines the reference time
@@ -36,12 +47,19 @@ type timeout struct {
 }
 
 func (t *timeout) Delay(n int, start time.Time) time.Duration {
+	delay, _ := t.Explain(n, start)
+	return delay
+}
+
+func (t *timeout) Explain(n int, start time.Time) (time.Duration, Cause) {
 	if t.clock.Time().Sub(start) >= t.limit {
-		return Exit
+		return Exit, CauseTimeout
 	}
-	return t.strategy.Delay(n, start)
+	return Explain(t.strategy, n, start)
 }
 
+func (t *timeout) Unwrap() Strategy { return t.strategy }
+
 // Timeout wraps a backoff [Strategy] to exit the retry cycle after the given
 // duration has passed. The elapsed time is measured relative to the time
 // supplied by clock. If limit <= 0, no timeout will be applied.
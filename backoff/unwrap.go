@@ -0,0 +1,45 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff
+
+// Unwrap returns the [Strategy] wrapped by a decorator. It calls the Unwrap
+// method of strategy, if present. Otherwise, Unwrap returns nil. All decorators
+// in this package implement such a method, and custom decorators are
+// encouraged to do the same.
+func Unwrap(strategy Strategy) Strategy {
+	u, ok := strategy.(interface{ Unwrap() Strategy })
+	if !ok {
+		return nil
+	}
+	return u.Unwrap()
+}
+
+// Bounded reports whether strategy is guaranteed to eventually return [Exit].
+// This is the case if the chain of decorators obtained by repeatedly calling
+// [Unwrap] contains a [Limit] or a [Timeout], or ends in [Once]. Custom
+// strategies are considered unbounded.
+func Bounded(strategy Strategy) bool {
+	for s := strategy; s != nil; s = Unwrap(s) {
+		switch s := s.(type) {
+		case *limit, *timeout:
+			return true
+		case *constant:
+			return s.d == Exit
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backoff_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestUnwrap(t *testing.T) {
+	exp := backoff.Constant(1 * time.Second)
+	act := backoff.Unwrap(backoff.Cap(exp, 1*time.Second))
+
+	if act != exp {
+		t.Errorf("unwrapped %v, want %v", act, exp)
+	}
+}
+
+func TestUnwrapNil(t *testing.T) {
+	act := backoff.Unwrap(backoff.Constant(1 * time.Second))
+
+	if act != nil {
+		t.Errorf("unwrapped %v, want nil", act)
+	}
+}
+
+func TestBounded(t *testing.T) {
+	for i, test := range []struct {
+		s   backoff.Strategy
+		exp bool
+	}{
+		{backoff.Constant(1 * time.Second), false},
+		{backoff.Once, true},
+		{backoff.Cap(backoff.Limit(backoff.Constant(1*time.Second), 3), 1*time.Second), true},
+		{backoff.Jitter(backoff.Timeout(backoff.Constant(1*time.Second), 1*time.Second, clock(time.Now())), 0.5, random(0.5)), true},
+		{backoff.Jitter(backoff.Cap(backoff.Constant(1*time.Second), 1*time.Second), 0.5, random(0.5)), false},
+	} {
+		act := backoff.Bounded(test.s)
+
+		if act != test.exp {
+			t.Errorf("#%d: bounded was %t, want %t", i, act, test.exp)
+		}
+	}
+}
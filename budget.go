@@ -0,0 +1,78 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// budget is the remaining budget of a retry cycle, which nested cycles
+// scheduled from within its attempts share. See [Cycler.Run].
+type budget struct {
+	mu       sync.Mutex
+	parent   *budget   // budget of the enclosing cycle, if any
+	retries  int       // remaining retries; negative if unlimited
+	deadline time.Time // end of the cycle; zero if unbounded
+}
+
+// budgetKey is the context key under which the budget of a cycle is stored.
+type budgetKey struct{}
+
+// budgetFrom extracts the budget of the enclosing cycle from ctx, if any.
+func budgetFrom(ctx context.Context) *budget {
+	b, _ := ctx.Value(budgetKey{}).(*budget)
+	return b
+}
+
+// newBudget creates the budget of a cycle that started at the given time, and
+// allows for at most limit attempts if limit > 0, and at most timeout if
+// timeout > 0. The budget is nested in parent, if not nil.
+func newBudget(
+	parent *budget,
+	start time.Time,
+	limit int,
+	timeout time.Duration,
+) *budget {
+	b := &budget{parent: parent, retries: limit - 1}
+	if timeout > 0 {
+		b.deadline = start.Add(timeout)
+	}
+	if parent != nil && !parent.deadline.IsZero() &&
+		(b.deadline.IsZero() || parent.deadline.Before(b.deadline)) {
+		b.deadline = parent.deadline
+	}
+	return b
+}
+
+// take consumes one retry from b and all enclosing budgets, and reports
+// whether each of them had one left.
+func (b *budget) take() bool {
+	for ; b != nil; b = b.parent {
+		b.mu.Lock()
+		ok := b.retries != 0
+		if b.retries > 0 {
+			b.retries--
+		}
+		b.mu.Unlock()
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
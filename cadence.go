@@ -0,0 +1,52 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import "time"
+
+// An Overrun policy determines how a fixed cadence deals with attempts that
+// run past the start of the next slot, see [Cycler.Cadence].
+type Overrun int
+
+const (
+	// CatchUp starts the next attempt immediately.
+	CatchUp Overrun = iota
+	// SkipMissed skips all slots that have already started, and waits for the
+	// next one.
+	SkipMissed
+)
+
+// schedule advances slot by delay, and returns the time left until the next
+// attempt is due, which is never negative.
+func schedule(
+	slot *time.Time,
+	delay time.Duration,
+	now time.Time,
+	overrun Overrun,
+) time.Duration {
+	*slot = slot.Add(delay)
+	late := now.Sub(*slot)
+	if late < 0 {
+		return -late
+	}
+	if overrun == SkipMissed && delay > 0 {
+		k := late/delay + 1 // number of slots to skip
+		*slot = slot.Add(k * delay)
+		return slot.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,70 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestCycler_Cadence(t *testing.T) {
+	for _, test := range []struct {
+		overrun retry.Overrun
+		took    []time.Duration
+		exp     []time.Duration
+	}{
+		{
+			overrun: retry.CatchUp,
+			took:    []time.Duration{3 * time.Minute, 25 * time.Minute},
+			exp:     []time.Duration{7 * time.Minute, 0},
+		},
+		{
+			overrun: retry.SkipMissed,
+			took:    []time.Duration{3 * time.Minute, 25 * time.Minute},
+			exp:     []time.Duration{7 * time.Minute, 5 * time.Minute},
+		},
+	} {
+		v := &virtual{now: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+		cycler := retry.NewCycler(backoff.Constant(10 * time.Minute))
+		cycler.Clock = v
+		cycler.Limit(len(test.took) + 1)
+		cycler.Cadence(test.overrun)
+
+		var act []time.Duration
+		cycler.OnError(func(n int, delay time.Duration, err error) {
+			act = append(act, delay)
+		})
+
+		_ = cycler.Try(func(n int) error {
+			if n <= len(test.took) {
+				// simulate the execution time
+				v.now = v.now.Add(test.took[n-1])
+			}
+			return ErrTest
+		})
+
+		for i := range test.exp {
+			if act[i] != test.exp[i] {
+				t.Errorf("delay #%d was %s, want %s", i+1, act[i], test.exp[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// inflight keeps track of the running retry cycles of a cycler, such that they
+// can be observed and cancelled individually.
+type inflight struct {
+	mu     sync.Mutex
+	cycles map[uint64]*flight // by cycle ID
+}
+
+// flight is the state of a running retry cycle.
+type flight struct {
+	cancel context.CancelFunc
+	Flight
+}
+
+// add registers the cycle with the given ID that started at start, and returns
+// a context derived from ctx that is cancelled once the cycle is cancelled.
+func (f *inflight) add(
+	ctx context.Context,
+	id uint64,
+	start time.Time,
+) context.Context {
+	ctx, cancel := context.WithCancel(ctx)
+	f.mu.Lock()
+	if f.cycles == nil {
+		f.cycles = make(map[uint64]*flight)
+	}
+	f.cycles[id] = &flight{
+		cancel: cancel,
+		Flight: Flight{ID: id, Start: start},
+	}
+	f.mu.Unlock()
+	return ctx
+}
+
+// schedule records that the cycle with the given ID made n attempts, and that
+// the next one is due at next. The zero time indicates that an attempt is
+// running.
+func (f *inflight) schedule(id uint64, n int, next time.Time) {
+	f.mu.Lock()
+	if x, ok := f.cycles[id]; ok {
+		x.Attempt = n
+		x.Next = next
+	}
+	f.mu.Unlock()
+}
+
+// remove unregisters the cycle with the given ID and releases its context.
+func (f *inflight) remove(id uint64) {
+	f.mu.Lock()
+	x := f.cycles[id]
+	delete(f.cycles, id)
+	f.mu.Unlock()
+	if x != nil {
+		x.cancel()
+	}
+}
+
+// Cancel aborts the running retry cycle with the given ID, as reported by
+// [Metadata], without affecting other work that shares its context. The cycle
+// then ends with [ContextCancelled] and returns [context.Canceled] as soon as
+// the current attempt or delay observes the cancellation. This allows an
+// operator endpoint or another subsystem to stop a single runaway cycle. The
+// method reports whether a running cycle with the given ID was found.
+func (c *Cycler) Cancel(id uint64) bool {
+	c.running.mu.Lock()
+	x, ok := c.running.cycles[id]
+	c.running.mu.Unlock()
+	if ok {
+		x.cancel()
+	}
+	return ok
+}
+
+// Running returns the IDs of the retry cycles that are currently running, in
+// ascending order. See [Cycler.Cancel].
+func (c *Cycler) Running() []uint64 {
+	c.running.mu.Lock()
+	ids := make([]uint64, 0, len(c.running.cycles))
+	for id := range c.running.cycles {
+		ids = append(ids, id)
+	}
+	c.running.mu.Unlock()
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// A Flight describes a retry cycle that is currently running. See
+// [Cycler.Flights].
+type Flight struct {
+	ID      uint64    // cycle ID, as reported by [Metadata]
+	Start   time.Time // time at which the cycle started
+	Attempt int       // number of attempts made so far
+	Next    time.Time // time of the next attempt; zero while an attempt runs
+}
+
+// Flights returns the retry cycles that are currently running, in ascending
+// order of their IDs. Unlike failure handlers, which report delays after the
+// fact, the time of the next attempt allows dashboards and operator tools to
+// show when a waiting cycle will try again. Times are measured by the clock of
+// the cycler (see [Cycler.Clock]).
+func (c *Cycler) Flights() []Flight {
+	c.running.mu.Lock()
+	flights := make([]Flight, 0, len(c.running.cycles))
+	for _, x := range c.running.cycles {
+		flights = append(flights, x.Flight)
+	}
+	c.running.mu.Unlock()
+	sort.Slice(flights, func(i, j int) bool {
+		return flights[i].ID < flights[j].ID
+	})
+	return flights
+}
@@ -0,0 +1,108 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestCycler_Cancel(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Hour))
+
+	ids := make(chan uint64, 1)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- cycler.Run(context.Background(), func(ctx context.Context, n int) error {
+			m, _ := retry.MetadataFrom(ctx)
+			ids <- m.Cycle
+			return ErrTest
+		})
+	}()
+
+	id := <-ids
+	if act := cycler.Running(); !reflect.DeepEqual(act, []uint64{id}) {
+		t.Errorf("running cycles were %v, want [%d]", act, id)
+	}
+	if !cycler.Cancel(id) {
+		t.Fatalf("cycle %d not found", id)
+	}
+
+	select {
+	case err := <-errs:
+		if err != context.Canceled {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("cycle was not cancelled")
+	}
+
+	if act := cycler.Running(); len(act) != 0 {
+		t.Errorf("running cycles were %v, want none", act)
+	}
+	if cycler.Cancel(id) {
+		t.Errorf("cycle %d was cancelled twice", id)
+	}
+}
+
+func TestCycler_Flights(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cycler := retry.NewCycler(backoff.Constant(37 * time.Second))
+	cycler.Clock = backoff.ClockFunc(func() time.Time { return now })
+
+	sleeping := make(chan struct{})
+	cycler.Sleeper = retry.SleeperFunc(func(ctx context.Context, d time.Duration) error {
+		close(sleeping)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ids := make(chan uint64, 1)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- cycler.Run(context.Background(), func(ctx context.Context, n int) error {
+			m, _ := retry.MetadataFrom(ctx)
+			ids <- m.Cycle
+			return ErrTest
+		})
+	}()
+
+	id := <-ids
+	<-sleeping
+
+	exp := []retry.Flight{{
+		ID:      id,
+		Start:   now,
+		Attempt: 1,
+		Next:    now.Add(37 * time.Second),
+	}}
+	if act := cycler.Flights(); !reflect.DeepEqual(act, exp) {
+		t.Errorf("flights were %+v, want %+v", act, exp)
+	}
+
+	cycler.Cancel(id)
+	<-errs
+	if act := cycler.Flights(); len(act) != 0 {
+		t.Errorf("flights were %+v, want none", act)
+	}
+}
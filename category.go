@@ -0,0 +1,151 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"errors"
+	"net"
+	"sync/atomic"
+)
+
+// A Category is a coarse class of failures, used to analyze which kinds of
+// errors are retried the most.
+type Category int
+
+const (
+	// CategoryUnknown is assigned to failures that fit no other category.
+	CategoryUnknown Category = iota
+	// CategoryNetwork is assigned to failures of the network or transport.
+	CategoryNetwork
+	// CategoryThrottle is assigned to failures due to rate limiting.
+	CategoryThrottle
+	// CategoryServer is assigned to failures on the side of a server.
+	CategoryServer
+	// CategoryClient is assigned to failures caused by invalid requests.
+	CategoryClient
+)
+
+// numCategories is the number of categories.
+const numCategories = int(CategoryClient) + 1
+
+var categories = [...]string{
+	CategoryUnknown:  "unknown",
+	CategoryNetwork:  "network",
+	CategoryThrottle: "throttle",
+	CategoryServer:   "server",
+	CategoryClient:   "client",
+}
+
+func (c Category) String() string {
+	if c < 0 || int(c) >= len(categories) {
+		return "unknown"
+	}
+	return categories[c]
+}
+
+// A Categorizer assigns a failure to a [Category]. See [Cycler.CategorizeBy].
+type Categorizer func(err error) Category
+
+// Categorize is the default [Categorizer]. It assigns the error chain of err
+// to a category as follows:
+//
+//   - [ErrThrottled] and status code 429 belong to [CategoryThrottle],
+//   - other status codes >= 500 belong to [CategoryServer],
+//   - other status codes >= 400 belong to [CategoryClient],
+//   - errors implementing [net.Error], and transient syscall errors (see
+//     [TransientErrno]) belong to [CategoryNetwork].
+//
+// Status codes are read from errors implementing a method StatusCode() int.
+// All other errors belong to [CategoryUnknown].
+func Categorize(err error) Category {
+	if errors.Is(err, ErrThrottled) {
+		return CategoryThrottle
+	}
+	var s interface{ StatusCode() int }
+	if errors.As(err, &s) {
+		switch code := s.StatusCode(); {
+		case code == 429:
+			return CategoryThrottle
+		case code >= 500:
+			return CategoryServer
+		case code >= 400:
+			return CategoryClient
+		}
+	}
+	var ne net.Error
+	if errors.As(err, &ne) || TransientErrno(err) {
+		return CategoryNetwork
+	}
+	return CategoryUnknown
+}
+
+// A CategoryInstrument is an [Instrument] that additionally observes the
+// category of each failure that is retried. Instruments registered with
+// [Cycler.Instrument] that implement this interface are detected
+// automatically.
+type CategoryInstrument interface {
+	Instrument
+	// ObserveCategory is called with the category of the error of the n-th
+	// attempt, right after the delay before the next retry was observed.
+	ObserveCategory(n int, category Category)
+}
+
+// taxonomy counts retries by category.
+type taxonomy struct {
+	counts [numCategories]uint64
+}
+
+// add records a retry in the given category.
+func (t *taxonomy) add(category Category) {
+	if category < 0 || int(category) >= numCategories {
+		category = CategoryUnknown
+	}
+	atomic.AddUint64(&t.counts[category], 1)
+}
+
+// snapshot returns the current counts by category.
+func (t *taxonomy) snapshot() map[Category]uint64 {
+	m := make(map[Category]uint64, numCategories)
+	for i := range t.counts {
+		m[Category(i)] = atomic.LoadUint64(&t.counts[i])
+	}
+	return m
+}
+
+// CategorizeBy sets the [Categorizer] that assigns retried failures to the
+// categories counted by [Cycler.Categories]. If categorizer is nil,
+// [Categorize] is used.
+func (c *Cycler) CategorizeBy(categorizer Categorizer) {
+	c.audit.touch()
+	c.category = categorizer
+}
+
+// Categories returns the number of retries scheduled by this cycler so far by
+// the [Category] of the failure that caused them. This tells what kinds of
+// failures are actually retried. All categories are included in the result,
+// even if their count is zero.
+func (c *Cycler) Categories() map[Category]uint64 {
+	return c.taxonomy.snapshot()
+}
+
+// categorize assigns err to a category.
+func (c *Cycler) categorize(err error) Category {
+	if c.category != nil {
+		return c.category(err)
+	}
+	return Categorize(err)
+}
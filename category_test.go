@@ -0,0 +1,118 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+// statusError is an error that carries a status code.
+type statusError int
+
+func (e statusError) Error() string   { return fmt.Sprintf("status %d", int(e)) }
+func (e statusError) StatusCode() int { return int(e) }
+
+func TestCategorize(t *testing.T) {
+	for _, test := range []struct {
+		err error
+		exp retry.Category
+	}{
+		{ErrTest, retry.CategoryUnknown},
+		{retry.ErrThrottled, retry.CategoryThrottle},
+		{statusError(429), retry.CategoryThrottle},
+		{fmt.Errorf("get: %w", statusError(503)), retry.CategoryServer},
+		{statusError(404), retry.CategoryClient},
+		{statusError(200), retry.CategoryUnknown},
+		{context.DeadlineExceeded, retry.CategoryNetwork},
+		{fmt.Errorf("read: %w", syscall.ECONNRESET), retry.CategoryNetwork},
+	} {
+		if act := retry.Categorize(test.err); act != test.exp {
+			t.Errorf("Categorize(%v) = %s, want %s", test.err, act, test.exp)
+		}
+	}
+}
+
+// categories records the categories observed by an instrument.
+type categories struct {
+	retry.Instrument
+	observed []retry.Category
+}
+
+func (c *categories) ObserveCategory(n int, category retry.Category) {
+	c.observed = append(c.observed, category)
+}
+
+func TestCycler_Categories(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Limit(4)
+	i := &categories{Instrument: retry.NewRecorder()}
+	cycler.Instrument(i)
+
+	errs := []error{statusError(503), retry.ErrThrottled, statusError(502)}
+	_ = cycler.Try(func(n int) error {
+		if n <= len(errs) {
+			return errs[n-1]
+		}
+		return nil
+	})
+
+	act := cycler.Categories()
+	if act[retry.CategoryServer] != 2 || act[retry.CategoryThrottle] != 1 {
+		t.Errorf("categories were %v", act)
+	}
+	if len(act) != 5 {
+		t.Errorf("got %d categories, want 5", len(act))
+	}
+	exp := []retry.Category{
+		retry.CategoryServer,
+		retry.CategoryThrottle,
+		retry.CategoryServer,
+	}
+	if fmt.Sprint(i.observed) != fmt.Sprint(exp) {
+		t.Errorf("observed %v, want %v", i.observed, exp)
+	}
+}
+
+func TestCycler_CategorizeBy(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Limit(2)
+	cycler.CategorizeBy(func(err error) retry.Category {
+		if errors.Is(err, ErrTest) {
+			return retry.CategoryClient
+		}
+		return retry.CategoryUnknown
+	})
+
+	_ = cycler.Try(func(n int) error { return ErrTest })
+
+	if act := cycler.Categories()[retry.CategoryClient]; act != 1 {
+		t.Errorf("client retries were %d, want 1", act)
+	}
+}
+
+func TestCategory_String(t *testing.T) {
+	if act, exp := retry.CategoryThrottle.String(), "throttle"; act != exp {
+		t.Errorf("String() = %q, want %q", act, exp)
+	}
+}
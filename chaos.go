@@ -0,0 +1,120 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+// ErrInjected is returned in place of attempts failed on purpose by [Chaos].
+var ErrInjected = errors.New("retry: injected failure")
+
+// Chaos configures fault injection for testing how systems behave under
+// worst-case retry behavior. See [Cycler.Chaos].
+type Chaos struct {
+	Seed        int64   // seed of the pseudo-random number generator
+	FailureRate float64 // probability of failing an attempt, in [0,1]
+	DelayRate   float64 // probability of inflating a delay, in [0,1]
+	DelayFactor float64 // factor by which delays are inflated, >= 1
+}
+
+// chaos injects faults according to its configuration.
+type chaos struct {
+	Chaos
+	mu sync.Mutex // guards rd
+	rd *rand.Rand
+}
+
+// roll reports whether an event of probability p occurs.
+func (ch *chaos) roll(p float64) bool {
+	if p <= 0 {
+		return false
+	}
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return ch.rd.Float64() < p
+}
+
+// wrap injects failures into attempt.
+func (ch *chaos) wrap(attempt ContextAttemptFunc) ContextAttemptFunc {
+	return func(ctx context.Context, n int) error {
+		if ch.roll(ch.FailureRate) {
+			return ErrInjected
+		}
+		return attempt(ctx, n)
+	}
+}
+
+// inflate wraps a backoff strategy to inflate delays at random.
+type inflate struct {
+	strategy backoff.Strategy
+	chaos    *chaos
+}
+
+func (in *inflate) Delay(n int, start time.Time) time.Duration {
+	delay, _ := in.Explain(n, start)
+	return delay
+}
+
+func (in *inflate) Explain(
+	n int,
+	start time.Time,
+) (time.Duration, backoff.Cause) {
+	delay, cause := backoff.Explain(in.strategy, n, start)
+	if delay == backoff.Exit || !in.chaos.roll(in.chaos.DelayRate) {
+		return delay, cause
+	}
+	return time.Duration(float64(delay) * in.chaos.DelayFactor), cause
+}
+
+func (in *inflate) Unwrap() backoff.Strategy { return in.strategy }
+
+// Chaos enables fault injection, which is meant for testing only. Attempts
+// fail with [ErrInjected] at the given failure rate, without being executed.
+// Delays between attempts are multiplied by the delay factor at the given
+// delay rate. The factor applies to the delays of the backoff strategy,
+// including decorators and limits, but before the cycler adjusts them for
+// progress (see [ProgressError]), [Cycler.Cadence] or [Cycler.Compensate].
+// The injected faults are fully determined by the seed. Passing the zero value
+// disables fault injection. The method panics if a rate is not in [0,1], or if
+// the delay factor is below 1 while the delay rate is positive.
+func (c *Cycler) Chaos(ch Chaos) {
+	c.audit.touch()
+	switch {
+	case ch.FailureRate < 0 || ch.FailureRate > 1:
+		panic(fmt.Sprintf("failure rate %f not in [0,1]", ch.FailureRate))
+	case ch.DelayRate < 0 || ch.DelayRate > 1:
+		panic(fmt.Sprintf("delay rate %f not in [0,1]", ch.DelayRate))
+	case ch.DelayRate > 0 && ch.DelayFactor < 1:
+		panic(fmt.Sprintf("delay factor = %f, must be >= 1", ch.DelayFactor))
+	}
+	if ch.FailureRate == 0 && ch.DelayRate == 0 {
+		c.chaos = nil
+		return
+	}
+	c.chaos = &chaos{
+		Chaos: ch,
+		rd:    rand.New(rand.NewSource(ch.Seed)),
+	}
+}
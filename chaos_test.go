@@ -0,0 +1,91 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestCycler_Chaos_Failures(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Chaos(retry.Chaos{Seed: 1, FailureRate: 1})
+	cycler.Limit(3)
+
+	executed := false
+	err := cycler.Try(func(n int) error {
+		executed = true
+		return nil
+	})
+
+	if executed {
+		t.Errorf("attempt was executed")
+	}
+	if !errors.Is(err, retry.ErrInjected) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCycler_Chaos_Delays(t *testing.T) {
+	const D = 1 * time.Second
+
+	newCycler := func() *retry.Cycler {
+		c := retry.NewCycler(backoff.Constant(D))
+		c.Chaos(retry.Chaos{Seed: 7, DelayRate: 0.5, DelayFactor: 10})
+		c.Limit(21)
+		c.Sleeper = retry.SleeperFunc(func(ctx context.Context, d time.Duration) error {
+			return nil
+		})
+		return c
+	}
+
+	ds, _ := delays(newCycler())
+
+	inflated := 0
+	for i, d := range ds {
+		switch d {
+		case D:
+		case 10 * D:
+			inflated++
+		default:
+			t.Errorf("#%d: unexpected delay: %s", i, d)
+		}
+	}
+	if inflated == 0 || inflated == len(ds) {
+		t.Errorf("%d of %d delays inflated", inflated, len(ds))
+	}
+
+	// faults are determined by the seed
+	if act, _ := delays(newCycler()); !reflect.DeepEqual(act, ds) {
+		t.Errorf("delays were %v, want %v", act, ds)
+	}
+}
+
+func TestCycler_Chaos_Panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for failure rate > 1")
+		}
+	}()
+	retry.NewCycler(backoff.Constant(0)).Chaos(retry.Chaos{FailureRate: 2})
+}
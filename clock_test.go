@@ -0,0 +1,67 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+// virtual is a backoff.Timer in which time only advances while waiting.
+type virtual struct {
+	now time.Time
+}
+
+func (v *virtual) Time() time.Time { return v.now }
+
+func (v *virtual) After(d time.Duration) <-chan time.Time {
+	v.now = v.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- v.now
+	return ch
+}
+
+func TestCycler_Clock_Timer(t *testing.T) {
+	v := &virtual{now: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	cycler := retry.NewCycler(backoff.Constant(10 * time.Minute))
+	cycler.Clock = v
+	cycler.Timeout(1 * time.Hour)
+
+	i := 0
+	start := time.Now()
+	err := cycler.Try(func(n int) error {
+		i = n
+		return ErrTest
+	})
+
+	if !errors.Is(err, ErrTest) {
+		t.Errorf("unexpected error: %#v", err)
+	}
+
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("cycle took %s in real time", d)
+	}
+
+	if i != 7 {
+		t.Errorf("attempts = %d, want 7", i)
+	}
+}
@@ -0,0 +1,276 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/printer"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// param describes a parameter of an interface method.
+type param struct {
+	Name string // generated name
+	Type string // type expression
+	Arg  string // expression passing the parameter on
+}
+
+// method describes an interface method.
+type method struct {
+	Name    string
+	Params  []param
+	Results []param
+	Ctx     bool // whether the first parameter is a context.Context
+	Err     bool // whether the last result is an error
+}
+
+// spec holds the data required to generate a wrapper.
+type spec struct {
+	Package string   // package name
+	Imports []string // import specs
+	Iface   string   // name of the interface
+	Type    string   // name of the wrapper
+	Methods []method
+}
+
+// generate emits the source of a wrapper type named typ around the interface
+// iface declared in one of the files, which must belong to the same package.
+func generate(
+	fset *token.FileSet,
+	files []*ast.File,
+	iface string,
+	typ string,
+) ([]byte, error) {
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, s := range gd.Specs {
+				ts := s.(*ast.TypeSpec)
+				if ts.Name.Name != iface {
+					continue
+				}
+				it, ok := ts.Type.(*ast.InterfaceType)
+				if !ok {
+					return nil, fmt.Errorf("%s is not an interface", iface)
+				}
+				if ts.TypeParams != nil {
+					return nil, fmt.Errorf(
+						"generic interface %s is not supported", iface,
+					)
+				}
+				return render(fset, f, it, iface, typ)
+			}
+		}
+	}
+	return nil, fmt.Errorf("interface %s not found", iface)
+}
+
+// render generates the wrapper for the interface it declared in file f.
+func render(
+	fset *token.FileSet,
+	f *ast.File,
+	it *ast.InterfaceType,
+	iface string,
+	typ string,
+) ([]byte, error) {
+	sp := spec{
+		Package: f.Name.Name,
+		Iface:   iface,
+		Type:    typ,
+	}
+	used := make(map[string]bool) // package names referenced by methods
+	expr := func(e ast.Expr) (string, error) {
+		ast.Inspect(e, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if id, ok := sel.X.(*ast.Ident); ok {
+					used[id.Name] = true
+				}
+			}
+			return true
+		})
+		var buf bytes.Buffer
+		if err := printer.Fprint(&buf, fset, e); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+
+	for _, field := range it.Methods.List {
+		if len(field.Names) == 0 {
+			return nil, fmt.Errorf("embedded interfaces are not supported")
+		}
+		ft := field.Type.(*ast.FuncType)
+		m := method{Name: field.Names[0].Name}
+		if ft.Params != nil {
+			for _, p := range ft.Params.List {
+				t, err := expr(p.Type)
+				if err != nil {
+					return nil, err
+				}
+				for i, k := 0, count(p); i < k; i++ {
+					name := "p" + strconv.Itoa(len(m.Params))
+					arg := name
+					if _, ok := p.Type.(*ast.Ellipsis); ok {
+						arg += "..."
+					}
+					m.Params = append(m.Params, param{name, t, arg})
+				}
+			}
+		}
+		if ft.Results != nil {
+			for _, r := range ft.Results.List {
+				t, err := expr(r.Type)
+				if err != nil {
+					return nil, err
+				}
+				for i, k := 0, count(r); i < k; i++ {
+					name := "r" + strconv.Itoa(len(m.Results))
+					m.Results = append(m.Results, param{name, t, name})
+				}
+			}
+		}
+		if k := len(m.Results); k > 0 && m.Results[k-1].Type == "error" {
+			m.Err = true
+			m.Results[k-1].Name = "err"
+		}
+		m.Ctx = len(m.Params) > 0 && m.Params[0].Type == "context.Context"
+		sp.Methods = append(sp.Methods, m)
+	}
+
+	sp.Imports = append(sp.Imports, strconv.Quote("github.com/deep-rent/retry"))
+	for _, imp := range f.Imports {
+		p, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			return nil, err
+		}
+		name := path.Base(p)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		if used[name] {
+			s := imp.Path.Value
+			if imp.Name != nil {
+				s = imp.Name.Name + " " + s
+			}
+			sp.Imports = append(sp.Imports, s)
+		}
+	}
+	sort.Strings(sp.Imports)
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, sp); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// count returns the number of names declared by field, which is at least 1.
+func count(field *ast.Field) int {
+	if len(field.Names) == 0 {
+		return 1
+	}
+	return len(field.Names)
+}
+
+var tmpl = template.Must(template.New("").Funcs(template.FuncMap{
+	"params": func(ps []param) string {
+		s := make([]string, len(ps))
+		for i, p := range ps {
+			s[i] = p.Name + " " + p.Type
+		}
+		return strings.Join(s, ", ")
+	},
+	"args": func(ps []param) string {
+		s := make([]string, len(ps))
+		for i, p := range ps {
+			s[i] = p.Arg
+		}
+		return strings.Join(s, ", ")
+	},
+	"names": func(ps []param) string {
+		s := make([]string, len(ps))
+		for i, p := range ps {
+			s[i] = p.Name
+		}
+		return strings.Join(s, ", ")
+	},
+}).Parse(`// Code generated by retrygen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	{{.}}
+{{- end}}
+)
+
+// {{.Type}} wraps a {{.Iface}} such that every method returning an error is
+// retried. Calls are scheduled by the cycler registered for the method name in
+// Methods, falling back to Cycler. Methods taking a context.Context as their
+// first argument receive the context of the current attempt. Methods that do
+// not return an error are passed through.
+type {{.Type}} struct {
+	Next    {{.Iface}}
+	Cycler  *retry.Cycler
+	Methods map[string]*retry.Cycler
+}
+
+// cycler returns the cycler responsible for the given method.
+func (rt *{{.Type}}) cycler(method string) *retry.Cycler {
+	if c, ok := rt.Methods[method]; ok {
+		return c
+	}
+	return rt.Cycler
+}
+{{range $m := .Methods}}
+{{- if not .Err}}
+func (rt *{{$.Type}}) {{.Name}}({{params .Params}})
+{{- if .Results}} ({{params .Results}}){{end}} {
+	{{if .Results}}return {{end}}rt.Next.{{.Name}}({{args .Params}})
+}
+{{else}}
+func (rt *{{$.Type}}) {{.Name}}({{params .Params}}) ({{params .Results}}) {
+{{- if .Ctx}}
+	err = rt.cycler("{{.Name}}").Run(p0, func(p0 context.Context, _ int) error {
+{{- else}}
+	err = rt.cycler("{{.Name}}").Try(func(_ int) error {
+{{- end}}
+{{- if eq (len .Results) 1}}
+		return rt.Next.{{.Name}}({{args .Params}})
+{{- else}}
+		var err error
+		{{names .Results}} = rt.Next.{{.Name}}({{args .Params}})
+		return err
+{{- end}}
+	})
+	return
+}
+{{end}}
+{{- end}}
+var _ {{.Iface}} = (*{{.Type}})(nil)
+`))
@@ -0,0 +1,105 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+const source = `package store
+
+import (
+	"context"
+	"io"
+	tm "time"
+)
+
+type Item struct{}
+
+type Store interface {
+	Get(ctx context.Context, id string) (*Item, error)
+	Put(id string, item *Item) error
+	List(ctx context.Context, ids ...string) ([]*Item, int, error)
+	Age(id string) tm.Duration
+	Reset()
+}
+
+var _ io.Reader
+`
+
+func TestGenerate(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "store.go", source, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	src, err := generate(fset, []*ast.File{f}, "Store", "RetryingStore")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "", src, 0); err != nil {
+		t.Fatalf("invalid output: %v\n%s", err, src)
+	}
+
+	out := string(src)
+	for _, exp := range []string{
+		"package store",
+		`"github.com/deep-rent/retry"`,
+		`tm "time"`,
+		"type RetryingStore struct",
+		`err = rt.cycler("Get").Run(p0, func(p0 context.Context, _ int) error {`,
+		"r0, err = rt.Next.Get(p0, p1)",
+		`err = rt.cycler("Put").Try(func(_ int) error {`,
+		"return rt.Next.Put(p0, p1)",
+		"r0, r1, err = rt.Next.List(p0, p1...)",
+		"return rt.Next.Age(p0)",
+		"func (rt *RetryingStore) Reset() {\n\trt.Next.Reset()\n}",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("output does not contain %q:\n%s", exp, out)
+		}
+	}
+	if strings.Contains(out, `"io"`) {
+		t.Errorf("output imports unused package:\n%s", out)
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	for i, test := range []struct {
+		src   string
+		iface string
+	}{
+		{"package p\ntype A interface{ M() error }", "B"},
+		{"package p\ntype A struct{}", "A"},
+		{"package p\ntype A interface{ B }\ntype B interface{}", "A"},
+		{"package p\ntype A[T any] interface{ M() T }", "A"},
+	} {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, "p.go", test.src, 0)
+		if err != nil {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+		if _, err := generate(fset, []*ast.File{f}, test.iface, "X"); err == nil {
+			t.Errorf("#%d: expected error", i)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Retrygen generates wrappers that execute the methods of an interface through
+// a retry.Cycler.
+//
+// Usage:
+//
+//	retrygen -type Client [-name RetryingClient] [-output file] [-dir .]
+//
+// Retrygen reads the Go package in the given directory, looks up the interface
+// named by -type, and writes a wrapper struct implementing the same interface.
+// Every method returning an error as its last result is retried, while other
+// methods are passed through. A cycler can be registered per method name to
+// override the default policy. The tool is meant to be run by go generate:
+//
+//	//go:generate retrygen -type Client
+//
+// By default, the wrapper is named after the interface prefixed by "Retrying",
+// and written to a file named after the interface suffixed by "_retry.go".
+// Embedded and generic interfaces are not supported. Imported packages are
+// matched by the last element of their path unless imported under an explicit
+// name.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	var (
+		iface = flag.String("type", "",
+			"name of the interface to wrap (required)")
+		name = flag.String("name", "",
+			"name of the wrapper (default \"Retrying<type>\")")
+		output = flag.String("output", "",
+			"output file (default \"<type>_retry.go\")")
+		dir = flag.String("dir", ".", "directory of the package")
+	)
+	flag.Parse()
+	if *iface == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *name == "" {
+		*name = "Retrying" + *iface
+	}
+	if *output == "" {
+		*output = strings.ToLower(*iface) + "_retry.go"
+	}
+	out := filepath.Join(*dir, *output)
+	if err := run(*dir, out, *iface, *name); err != nil {
+		fmt.Fprintf(os.Stderr, "retrygen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run generates the wrapper typ for iface declared in the package in dir, and
+// writes it to out.
+func run(dir, out, iface, typ string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, p := range paths {
+		if strings.HasSuffix(p, "_test.go") ||
+			filepath.Clean(p) == filepath.Clean(out) {
+			continue
+		}
+		f, err := parser.ParseFile(fset, p, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+	}
+	src, err := generate(fset, files, iface, typ)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(out, src, 0o644)
+}
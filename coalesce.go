@@ -0,0 +1,79 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"fmt"
+	"time"
+)
+
+// A CoalescedError is passed to error and failure handlers in place of the
+// error of an attempt if the failures preceding it were held back by
+// [Cycler.Coalesce]. It unwraps to the error of the attempt.
+type CoalescedError struct {
+	Err   error     // error of the current attempt
+	Held  int       // number of failures held back before this one
+	Since time.Time // time of the first failure held back
+}
+
+func (e *CoalescedError) Error() string {
+	return fmt.Sprintf("%v (after %d more failures since %s)",
+		e.Err, e.Held, e.Since.Format(time.RFC3339))
+}
+
+func (e *CoalescedError) Unwrap() error { return e.Err }
+
+// coalescer limits the rate at which the failures of a cycle are passed to
+// handlers.
+type coalescer struct {
+	interval time.Duration // minimum time between notifications
+	last     time.Time     // time of the last notification
+	held     int           // number of failures held back since then
+	since    time.Time     // time of the first failure held back
+}
+
+// admit reports whether the failure with error err that occurred at now is
+// passed on to the handlers, and if so, the error to pass on. The final
+// failure of a cycle is always passed on.
+func (b *coalescer) admit(err error, now time.Time, final bool) (error, bool) {
+	if !final && !b.last.IsZero() && now.Sub(b.last) < b.interval {
+		if b.held == 0 {
+			b.since = now
+		}
+		b.held++
+		return nil, false
+	}
+	b.last = now
+	if b.held == 0 {
+		return err, true
+	}
+	err = &CoalescedError{Err: err, Held: b.held, Since: b.since}
+	b.held = 0
+	return err, true
+}
+
+// Coalesce limits the invocations of error and failure handlers to at most one
+// per interval and cycle, which reduces the logging overhead of very hot
+// cyclers. Failures in between are held back; the next failure that is passed
+// on carries their number in a [CoalescedError]. The first failure and the
+// final failure of a cycle are always passed on. Unlike [Cycler.Sample],
+// coalescing does not affect instruments, and applies to each cycle on its
+// own. If interval <= 0, handlers are invoked for every failure.
+func (c *Cycler) Coalesce(interval time.Duration) {
+	c.audit.touch()
+	c.coalesce = interval
+}
@@ -0,0 +1,70 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestCycler_Coalesce(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cycler := retry.NewCycler(backoff.Constant(300 * time.Millisecond))
+	cycler.Clock = backoff.ClockFunc(func() time.Time { return now })
+	cycler.Sleeper = retry.SleeperFunc(func(_ context.Context, d time.Duration) error {
+		now = now.Add(d)
+		return nil
+	})
+	cycler.Limit(8)
+	cycler.Coalesce(1 * time.Second)
+
+	var (
+		errs     []error
+		failures []int
+	)
+	cycler.OnError(func(n int, delay time.Duration, err error) {
+		errs = append(errs, err)
+	})
+	cycler.OnFailure(func(f retry.Failure) {
+		failures = append(failures, f.Attempt)
+	})
+
+	_ = cycler.Try(func(n int) error { return ErrTest })
+
+	// failures occur every 300ms: attempts 1, 5 and the final attempt 8 are
+	// passed on
+	if exp := []int{1, 5, 8}; !reflect.DeepEqual(failures, exp) {
+		t.Errorf("failures were %v, want %v", failures, exp)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("error handlers were called %d times, want 2", len(errs))
+	}
+	if errs[0] != ErrTest {
+		t.Errorf("unexpected error: %v", errs[0])
+	}
+	var ce *retry.CoalescedError
+	if !errors.As(errs[1], &ce) || ce.Held != 3 || !errors.Is(ce, ErrTest) {
+		t.Errorf("unexpected error: %v", errs[1])
+	}
+}
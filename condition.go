@@ -0,0 +1,164 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import "time"
+
+// A State describes the progress of a retry cycle after a failed attempt. It
+// is passed to stop conditions, see [Cycler.StopWhen].
+type State struct {
+	Attempt int           // number of attempts so far
+	Elapsed time.Duration // time elapsed since the cycle started
+	Waited  time.Duration // cumulative time spent waiting between attempts
+	Err     error         // error returned by the last attempt
+}
+
+// A StopFunc reports whether a retry cycle should stop in the given state.
+type StopFunc func(s State) bool
+
+// A Condition decides when retry cycles stop. Conditions are instantiated at
+// the start of each cycle, which allows them to keep state across the attempts
+// of a cycle. Conditions compose with [AnyOf] and [AllOf].
+type Condition func() StopFunc
+
+// When returns a stateless [Condition] that stops retry cycles as soon as fn
+// returns true.
+func When(fn StopFunc) Condition {
+	return func() StopFunc { return fn }
+}
+
+// AfterAttempts returns a [Condition] that stops retry cycles after n
+// attempts, including the initial attempt.
+func AfterAttempts(n int) Condition {
+	return When(func(s State) bool { return s.Attempt >= n })
+}
+
+// AfterElapsed returns a [Condition] that stops retry cycles once d has elapsed
+// since they started.
+func AfterElapsed(d time.Duration) Condition {
+	return When(func(s State) bool { return s.Elapsed >= d })
+}
+
+// AfterWaited returns a [Condition] that stops retry cycles once the time spent
+// waiting between attempts adds up to d.
+func AfterWaited(d time.Duration) Condition {
+	return When(func(s State) bool { return s.Waited >= d })
+}
+
+// A CostFunc assigns a cost to the failure of an attempt, see [AfterBudget].
+type CostFunc func(err error) int
+
+// AfterBudget returns a [Condition] that stops retry cycles once the failures
+// of their attempts have used up a budget of max, where each failure costs as
+// much as cost assigns to its error. This way, expensive failures, such as
+// timeouts, exhaust the budget sooner than cheap ones. If cost is nil, each
+// failure costs 1, which makes the condition equivalent to [AfterAttempts].
+func AfterBudget(max int, cost CostFunc) Condition {
+	return func() StopFunc {
+		spent := 0 // cost of the failures so far
+		return func(s State) bool {
+			if cost == nil {
+				spent++
+			} else {
+				spent += cost(s.Err)
+			}
+			return spent >= max
+		}
+	}
+}
+
+// OnSignal returns a [Condition] that stops retry cycles once signal is closed.
+func OnSignal(signal <-chan struct{}) Condition {
+	return When(func(State) bool {
+		select {
+		case <-signal:
+			return true
+		default:
+			return false
+		}
+	})
+}
+
+// OnRepeat returns a [Condition] that stops retry cycles once m consecutive
+// attempts failed with errors of the same fingerprint, as computed by fn. See
+// [Cycler.Fingerprint] for details.
+func OnRepeat(fn FingerprintFunc, m int) Condition {
+	r := &repeat{print: fn, m: m}
+	return func() StopFunc {
+		rs := repeats{repeat: r}
+		return func(s State) bool { return rs.observe(s.Err) }
+	}
+}
+
+// AnyOf returns a [Condition] that stops retry cycles as soon as any of the
+// given conditions holds. All conditions are evaluated in each state, such that
+// stateful conditions observe every attempt.
+func AnyOf(conds ...Condition) Condition {
+	return combine(conds, false)
+}
+
+// AllOf returns a [Condition] that stops retry cycles as soon as all of the
+// given conditions hold at the same time. All conditions are evaluated in each
+// state, such that stateful conditions observe every attempt.
+func AllOf(conds ...Condition) Condition {
+	return combine(conds, true)
+}
+
+// combine joins conds by conjunction if and is set, or by disjunction
+// otherwise. Empty combinations never stop.
+func combine(conds []Condition, and bool) Condition {
+	return func() StopFunc {
+		fns := make([]StopFunc, len(conds))
+		for i, cond := range conds {
+			fns[i] = cond()
+		}
+		return func(s State) bool {
+			if len(fns) == 0 {
+				return false
+			}
+			stop := and
+			for _, fn := range fns {
+				if fn(s) != and {
+					stop = !and
+				}
+			}
+			return stop
+		}
+	}
+}
+
+// StopWhen sets the conditions under which retry cycles stop, in addition to
+// the limits imposed by the backoff strategy. The conditions are combined with
+// [AnyOf], and are checked after each failed attempt. Unlike decorators such
+// as [Cycler.Limit], whose effect depends on the order in which they are
+// applied, conditions combine declaratively, e.g.
+//
+//	c.StopWhen(
+//		retry.AfterAttempts(10),
+//		retry.AllOf(retry.AfterElapsed(time.Minute), retry.AfterAttempts(3)),
+//	)
+//
+// Cycles stopped by a condition end with [LimitReached]. Calling StopWhen again
+// replaces the previous conditions. If no conditions are given, none apply.
+func (c *Cycler) StopWhen(conds ...Condition) {
+	c.audit.touch()
+	if len(conds) == 0 {
+		c.stop = nil
+		return
+	}
+	c.stop = AnyOf(conds...)
+}
@@ -0,0 +1,144 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+// attempts runs a retry cycle that always fails, and returns the number of
+// attempts it made.
+func attempts(cycler *retry.Cycler, errs ...error) int {
+	i := 0
+	_ = cycler.Try(func(n int) error {
+		i++
+		if len(errs) == 0 {
+			return ErrTest
+		}
+		return errs[(n-1)%len(errs)]
+	})
+	return i
+}
+
+func TestCycler_StopWhen(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.StopWhen(retry.AfterAttempts(4), retry.AfterAttempts(2))
+
+	if act := attempts(cycler); act != 2 {
+		t.Errorf("got %d attempts, want 2", act)
+	}
+
+	cycler.StopWhen()
+	cycler.Limit(3)
+
+	if act := attempts(cycler); act != 3 {
+		t.Errorf("got %d attempts, want 3", act)
+	}
+}
+
+func TestAfterBudget(t *testing.T) {
+	errSlow := errors.New("slow")
+	cost := func(err error) int {
+		if errors.Is(err, errSlow) {
+			return 3
+		}
+		return 1
+	}
+
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.StopWhen(retry.AfterBudget(5, cost))
+
+	// costs 1 + 3 + 1 = 5
+	if act := attempts(cycler, ErrTest, errSlow, ErrTest); act != 3 {
+		t.Errorf("got %d attempts, want 3", act)
+	}
+	// each cycle starts with a fresh budget
+	if act := attempts(cycler, errSlow, errSlow); act != 2 {
+		t.Errorf("got %d attempts, want 2", act)
+	}
+}
+
+func TestAllOf(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Second))
+	cycler.Sleeper = retry.SleeperFunc(func(ctx context.Context, d time.Duration) error {
+		return nil
+	})
+	cycler.StopWhen(retry.AllOf(
+		retry.AfterAttempts(2),
+		retry.AfterWaited(3*time.Second),
+	))
+
+	if act := attempts(cycler); act != 4 {
+		t.Errorf("got %d attempts, want 4", act)
+	}
+}
+
+func TestOnRepeat(t *testing.T) {
+	errA, errB := errors.New("a"), errors.New("b")
+
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.StopWhen(retry.OnRepeat(func(err error) string { return err.Error() }, 2))
+
+	// a, b, b
+	if act := attempts(cycler, errA, errB, errB); act != 3 {
+		t.Errorf("got %d attempts, want 3", act)
+	}
+	// the state is reset between cycles
+	if act := attempts(cycler, errA, errA); act != 2 {
+		t.Errorf("got %d attempts, want 2", act)
+	}
+}
+
+func TestOnSignal(t *testing.T) {
+	signal := make(chan struct{})
+
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.StopWhen(retry.OnSignal(signal), retry.AfterAttempts(5))
+
+	if act := attempts(cycler); act != 5 {
+		t.Errorf("got %d attempts, want 5", act)
+	}
+	close(signal)
+	if act := attempts(cycler); act != 1 {
+		t.Errorf("got %d attempts, want 1", act)
+	}
+}
+
+func TestAfterElapsed(t *testing.T) {
+	now := time.Now()
+
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Clock = backoff.ClockFunc(func() time.Time { return now })
+	cycler.StopWhen(retry.AfterElapsed(1 * time.Minute))
+
+	i := 0
+	_ = cycler.Try(func(n int) error {
+		i++
+		now = now.Add(25 * time.Second)
+		return ErrTest
+	})
+
+	if i != 3 {
+		t.Errorf("got %d attempts, want 3", i)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"context"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+// A Verdict tells a message consumer how to settle a message.
+type Verdict int
+
+const (
+	// Ack indicates that the message was handled and can be acknowledged.
+	Ack Verdict = iota
+	// Requeue indicates that the message should be redelivered after a delay.
+	Requeue
+	// DeadLetter indicates that the message should no longer be redelivered,
+	// but moved to a dead-letter queue instead.
+	DeadLetter
+)
+
+var verdicts = [...]string{
+	Ack:        "ack",
+	Requeue:    "requeue",
+	DeadLetter: "dead-letter",
+}
+
+func (v Verdict) String() string {
+	if v < 0 || int(v) >= len(verdicts) {
+		return "unknown"
+	}
+	return verdicts[v]
+}
+
+// A Decision is the outcome of handling a single delivery of a message.
+type Decision struct {
+	Verdict Verdict       // how to settle the message
+	Delay   time.Duration // redelivery delay if the verdict is Requeue
+	At      time.Time     // redelivery time if the verdict is Requeue
+	Err     error         // error returned by the handler, if any
+}
+
+// A Handler processes a message of type T.
+type Handler[T any] func(ctx context.Context, msg T) error
+
+// Consume handles a single delivery of msg, treating each delivery as one
+// attempt of a retry cycle scheduled by c. The count is the number of times
+// the message has been delivered so far, starting at count = 1, and first is
+// the time of its first delivery. Both are usually provided by the message
+// broker.
+//
+// If handler returns nil, the message is acknowledged. If it fails, the backoff
+// strategy of c determines the delay after which the message should be
+// redelivered, which typically maps to a visibility timeout or requeue delay
+// (see [backoff.Redeliver]).
+// The message is dead-lettered once the strategy signals the end of the cycle,
+// or if the error is an [ExitError] or not classified as retryable by
+// [Cycler.RetryIf]. If ctx is done by the time handler returns, the message is
+// requeued without delay, since it was not necessarily processed.
+//
+// This helper does not wait between attempts, nor does it invoke the handlers
+// registered with c; it merely shares the retry policy of c, such that the
+// same policies can be applied to in-process and broker-based retries alike.
+func Consume[T any](
+	ctx context.Context,
+	c *Cycler,
+	msg T,
+	count int,
+	first time.Time,
+	handler Handler[T],
+) Decision {
+	err := handler(ctx, msg)
+	if err == nil {
+		return Decision{Verdict: Ack}
+	}
+	if ctx.Err() != nil {
+		return Decision{Verdict: Requeue, Err: err}
+	}
+	if e, ok := err.(*ExitError); ok {
+		return Decision{Verdict: DeadLetter, Err: e.Cause}
+	}
+	if c.classifier != nil && !c.classifier(err) {
+		return Decision{Verdict: DeadLetter, Err: err}
+	}
+
+	sd := c.seed
+	if !c.seeded {
+		sd = seed()
+	}
+	cy := newCycle(sd)
+	strategy := c.build(ctx, cy)
+	if cy.sw != nil {
+		cy.sw.observe(err)
+	}
+	r := backoff.Redeliver(strategy, count, first, c.Clock.Time())
+	if r.DeadLetter {
+		return Decision{Verdict: DeadLetter, Err: err}
+	}
+	return Decision{Verdict: Requeue, Delay: r.Delay, At: r.At, Err: err}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+var ErrFatal = errors.New("fatal")
+
+func TestConsume(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Linear(1*time.Second, 1*time.Second))
+	cycler.Limit(3)
+	cycler.RetryIf(func(err error) bool { return !errors.Is(err, ErrFatal) })
+
+	first := time.Now()
+	for i, test := range []struct {
+		err   error
+		count int
+		exp   retry.Decision
+	}{
+		{nil, 1, retry.Decision{Verdict: retry.Ack}},
+		{ErrTest, 1, retry.Decision{Verdict: retry.Requeue, Delay: 1 * time.Second, Err: ErrTest}},
+		{ErrTest, 2, retry.Decision{Verdict: retry.Requeue, Delay: 2 * time.Second, Err: ErrTest}},
+		{ErrTest, 3, retry.Decision{Verdict: retry.DeadLetter, Err: ErrTest}},
+		{retry.ForceExit(ErrTest), 1, retry.Decision{Verdict: retry.DeadLetter, Err: ErrTest}},
+		{ErrFatal, 1, retry.Decision{Verdict: retry.DeadLetter, Err: ErrFatal}},
+	} {
+		act := retry.Consume(
+			context.Background(), cycler, "msg", test.count, first,
+			func(ctx context.Context, msg string) error {
+				if msg != "msg" {
+					t.Fatalf("#%d: unexpected message: %q", i, msg)
+				}
+				return test.err
+			},
+		)
+		act.At = time.Time{}
+		if act != test.exp {
+			t.Errorf("#%d: decision was %+v, want %+v", i, act, test.exp)
+		}
+	}
+}
+
+func TestConsume_Cancelled(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Second))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	act := retry.Consume(ctx, cycler, 0, 1, time.Now(),
+		func(ctx context.Context, msg int) error { return ctx.Err() },
+	)
+	if act.Verdict != retry.Requeue || act.Delay != 0 {
+		t.Errorf("unexpected decision: %+v", act)
+	}
+}
+
+func TestVerdict_String(t *testing.T) {
+	if act, exp := retry.DeadLetter.String(), "dead-letter"; act != exp {
+		t.Errorf("String() = %q, want %q", act, exp)
+	}
+}
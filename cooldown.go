@@ -0,0 +1,49 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+// cooldown keeps a cycler in a failing state after a cycle gave up.
+type cooldown struct {
+	mu    sync.Mutex
+	d     time.Duration // duration of the failing state
+	until time.Time     // end of the failing state
+	err   error         // error returned by the cycle that gave up
+}
+
+// check returns the error of the cycle that gave up last if the failing state
+// is still active at time now, and nil otherwise.
+func (b *cooldown) check(now time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err == nil || !now.Before(b.until) {
+		return nil
+	}
+	return b.err
+}
+
+// trip enters the failing state at time now, remembering err.
+func (b *cooldown) trip(now time.Time, err error) {
+	b.mu.Lock()
+	b.until = now.Add(b.d)
+	b.err = err
+	b.mu.Unlock()
+}
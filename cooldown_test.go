@@ -0,0 +1,58 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestCycler_Cooldown(t *testing.T) {
+	const D = 20 * time.Millisecond
+
+	v := &virtual{now: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Clock = v
+	cycler.Limit(2)
+	cycler.Cooldown(D)
+
+	err := cycler.Try(func(n int) error { return ErrTest })
+	if !errors.Is(err, ErrTest) {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	// fails fast while cooling down
+	err = cycler.Try(func(n int) error {
+		t.Errorf("unexpected attempt")
+		return nil
+	})
+
+	if !errors.Is(err, ErrTest) {
+		t.Errorf("unexpected error: %#v", err)
+	}
+
+	v.now = v.now.Add(D)
+
+	if err := cycler.Try(func(n int) error { return nil }); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+var (
+	mu sync.Mutex // guards rd
+	rd = rand.New(rand.NewSource(time.Now().UTC().UnixNano()))
+)
+
+// seed returns a new pseudo-random seed.
+func seed() int64 {
+	mu.Lock()
+	defer mu.Unlock()
+	return rd.Int63()
+}
+
+// A cycle holds the state of a single retry cycle.
+type cycle struct {
+	seed  int64          // seed of the pseudo-random number generator
+	state uint64         // state of the pseudo-random number generator
+	seq   backoff.Random // replaces the generator if set
+	sw    *switcher      // switches strategies if set
+}
+
+func newCycle(seed int64) *cycle {
+	return &cycle{
+		seed:  seed,
+		state: uint64(seed),
+	}
+}
+
+// random implements [backoff.Random] based on the SplitMix64 algorithm, unless
+// seq is set. The generated numbers are fully determined by the seed of the
+// cycle.
+func (cy *cycle) random() float64 {
+	if cy.seq != nil {
+		return cy.seq()
+	}
+	cy.state += 0x9e3779b97f4a7c15
+	z := cy.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	z ^= z >> 31
+	return float64(z>>11) / (1 << 53)
+}
+
+// A decorator wraps the backoff strategy of a retry cycle. Decorators are
+// applied anew at the start of each cycle, which allows them to draw on the
+// state of the cycle.
+type decorator func(strategy backoff.Strategy, cy *cycle) backoff.Strategy
@@ -0,0 +1,99 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+// delays runs a failing retry cycle and returns the delays in between.
+func delays(cycler *retry.Cycler) ([]time.Duration, error) {
+	var ds []time.Duration
+	cycler.OnError(func(n int, delay time.Duration, err error) {
+		ds = append(ds, delay)
+	})
+	err := cycler.Try(func(n int) error { return ErrTest })
+	return ds, err
+}
+
+func TestCycler_Seed(t *testing.T) {
+	newCycler := func() *retry.Cycler {
+		c := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+		c.Jitter(0.9)
+		c.Limit(5)
+		c.History(1)
+		return c
+	}
+
+	// record a cycle with a random seed
+	exp, err := delays(newCycler())
+
+	var e *retry.CycleError
+	if !errors.As(err, &e) {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	// replay the cycle
+	c := newCycler()
+	c.Seed(e.Seed)
+	act, _ := delays(c)
+
+	if len(act) != len(exp) {
+		t.Fatalf("replayed %d delays, want %d", len(act), len(exp))
+	}
+	for i := range exp {
+		if act[i] != exp[i] {
+			t.Errorf("delay #%d was %s, want %s", i+1, act[i], exp[i])
+		}
+	}
+}
+
+func TestCycler_LowDiscrepancy(t *testing.T) {
+	const D = 1 * time.Second
+
+	cycler := retry.NewCycler(backoff.Constant(D))
+	cycler.LowDiscrepancy(true)
+	cycler.Jitter(0.5)
+	cycler.Limit(4)
+	cycler.Sleeper = retry.SleeperFunc(func(ctx context.Context, d time.Duration) error {
+		return nil
+	})
+
+	ds, _ := delays(cycler)
+	if len(ds) != 3 {
+		t.Fatalf("got %d delays, want 3", len(ds))
+	}
+
+	// consecutive golden ratio steps are at least 0.236 apart (modulo 1),
+	// which translates into 23.6% of the jitter range
+	const gap = time.Duration(0.236 * float64(D))
+	for i := 1; i < len(ds); i++ {
+		d := ds[i] - ds[i-1]
+		if d < 0 {
+			d = -d
+		}
+		if d < gap {
+			t.Errorf("delays #%d and #%d too close: %s, %s", i, i+1, ds[i-1], ds[i])
+		}
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrDebounced is returned if a retry cycle is rejected because the previous
+// cycle ended too recently, see [Cycler.Debounce].
+var ErrDebounced = errors.New("retry: debounced")
+
+// debounce enforces a minimum gap between consecutive retry cycles.
+type debounce struct {
+	mu    sync.Mutex
+	gap   time.Duration // minimum time between consecutive cycles
+	block bool          // whether to wait instead of failing
+	end   time.Time     // end of the most recent cycle
+}
+
+// admit decides whether a new cycle may start at time now. If not, it either
+// waits by means of sleeper until the gap has passed, or fails with
+// [ErrDebounced].
+func (b *debounce) admit(
+	ctx context.Context,
+	now time.Time,
+	sleeper Sleeper,
+) error {
+	b.mu.Lock()
+	wait := b.end.Add(b.gap).Sub(now)
+	b.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	if !b.block {
+		return ErrDebounced
+	}
+	return sleeper.Sleep(ctx, wait)
+}
+
+// done records the end of a cycle at time now.
+func (b *debounce) done(now time.Time) {
+	b.mu.Lock()
+	b.end = now
+	b.mu.Unlock()
+}
@@ -0,0 +1,62 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestCycler_Debounce(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Debounce(time.Hour, false)
+
+	succeed := func(n int) error { return nil }
+
+	if err := cycler.Try(succeed); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := cycler.Try(succeed); err != retry.ErrDebounced {
+		t.Errorf("unexpected error: %#v", err)
+	}
+}
+
+func TestCycler_Debounce_Block(t *testing.T) {
+	const D = 20 * time.Millisecond
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	v := &virtual{now: start}
+
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Clock = v
+	cycler.Debounce(D, true)
+
+	succeed := func(n int) error { return nil }
+	_ = cycler.Try(succeed)
+
+	if err := cycler.Try(succeed); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if d := v.now.Sub(start); d != D {
+		t.Errorf("waited %s, want %s", d, D)
+	}
+}
@@ -0,0 +1,55 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"context"
+	"fmt"
+)
+
+// limitKey is the context key under which the attempt limit set by [LimitTo]
+// is stored.
+type limitKey struct{}
+
+// LimitTo returns a copy of ctx that limits any retry cycle running within it
+// to at most n attempts, regardless of the configuration of the cycler that
+// schedules the cycle. The limit takes precedence even over
+// [Cycler.MinAttempts]. If ctx is already limited to fewer attempts, the lower
+// limit remains in effect. This allows specific call paths to restrict retries
+// without reconfiguring shared cyclers. The function panics if n < 1.
+func LimitTo(ctx context.Context, n int) context.Context {
+	if n < 1 {
+		panic(fmt.Sprintf("n = %d, must be >= 1", n))
+	}
+	if m, ok := limitFrom(ctx); ok && m <= n {
+		return ctx
+	}
+	return context.WithValue(ctx, limitKey{}, n)
+}
+
+// Disable returns a copy of ctx in which retries are turned off, i.e., any
+// retry cycle running within it makes a single attempt. It is shorthand for
+// LimitTo(ctx, 1), and particularly useful in unit tests or while debugging.
+func Disable(ctx context.Context) context.Context {
+	return LimitTo(ctx, 1)
+}
+
+// limitFrom extracts the attempt limit set by [LimitTo] from ctx, if any.
+func limitFrom(ctx context.Context) (int, bool) {
+	n, ok := ctx.Value(limitKey{}).(int)
+	return n, ok
+}
@@ -0,0 +1,80 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestDisable(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Hour))
+	cycler.MinAttempts(3)
+
+	i := 0
+	err := cycler.TryWithContext(retry.Disable(context.Background()), func(n int) error {
+		i = n
+		return ErrTest
+	})
+
+	if !errors.Is(err, retry.ErrLimitExceeded) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if i != 1 {
+		t.Errorf("attempts = %d, want 1", i)
+	}
+}
+
+func TestLimitTo(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Limit(5)
+
+	for _, test := range []struct {
+		ctx context.Context
+		exp int
+	}{
+		{context.Background(), 5},
+		{retry.LimitTo(context.Background(), 3), 3},
+		{retry.LimitTo(context.Background(), 10), 5},
+		// the lower limit remains in effect
+		{retry.LimitTo(retry.LimitTo(context.Background(), 2), 4), 2},
+		{retry.LimitTo(retry.Disable(context.Background()), 4), 1},
+	} {
+		i := 0
+		_ = cycler.TryWithContext(test.ctx, func(n int) error {
+			i = n
+			return ErrTest
+		})
+		if i != test.exp {
+			t.Errorf("attempts = %d, want %d", i, test.exp)
+		}
+	}
+}
+
+func TestLimitTo_Invalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	retry.LimitTo(context.Background(), 0)
+}
@@ -0,0 +1,46 @@
+//go:build !plan9
+
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"errors"
+	"syscall"
+)
+
+// TransientErrno is a [Classifier] for low-level file and socket code. It
+// reports whether err wraps a raw syscall error that is commonly worth
+// retrying, namely EAGAIN, EINTR, ECONNRESET, ETIMEDOUT, or EADDRNOTAVAIL. The
+// error chain is unwrapped, such that errors wrapped in an [os.SyscallError],
+// an [os.PathError] or a [net.OpError] are detected as well.
+func TransientErrno(err error) bool {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return false
+	}
+	switch errno {
+	case syscall.EAGAIN,
+		syscall.EINTR,
+		syscall.ECONNRESET,
+		syscall.ETIMEDOUT,
+		syscall.EADDRNOTAVAIL:
+		return true
+	default:
+		return false
+	}
+}
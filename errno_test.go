@@ -0,0 +1,71 @@
+//go:build !plan9
+
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestTransientErrno(t *testing.T) {
+	for i, test := range []struct {
+		err error
+		exp bool
+	}{
+		{syscall.EAGAIN, true},
+		{os.NewSyscallError("read", syscall.EINTR), true},
+		{&fs.PathError{Op: "open", Path: "x", Err: syscall.ETIMEDOUT}, true},
+		{fmt.Errorf("wrapped: %w", os.NewSyscallError("connect", syscall.ECONNRESET)), true},
+		{syscall.EADDRNOTAVAIL, true},
+		{syscall.ENOENT, false},
+		{ErrTest, false},
+	} {
+		if act := retry.TransientErrno(test.err); act != test.exp {
+			t.Errorf("#%d: transient was %t, want %t", i, act, test.exp)
+		}
+	}
+}
+
+func TestCycler_RetryIf(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.RetryIf(retry.TransientErrno)
+
+	err := cycler.Try(func(n int) error {
+		switch n {
+		case 1:
+			return os.NewSyscallError("read", syscall.EAGAIN)
+		case 2:
+			return os.NewSyscallError("open", syscall.ENOENT)
+		default:
+			t.Fatalf("too many attempts: n > 2")
+			return nil
+		}
+	})
+
+	if !os.IsNotExist(err) {
+		t.Errorf("unexpected error: %#v", err)
+	}
+}
@@ -0,0 +1,50 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+// A FingerprintFunc computes the fingerprint of an error, such that errors
+// with the same cause share the same fingerprint. See [Cycler.Fingerprint].
+type FingerprintFunc func(err error) string
+
+// repeat configures the maximum number of identical consecutive failures.
+type repeat struct {
+	print FingerprintFunc
+	m     int // maximum number of consecutive identical fingerprints
+}
+
+// repeats tracks consecutive fingerprints within a retry cycle.
+type repeats struct {
+	*repeat
+	last  string // most recent fingerprint
+	count int    // number of consecutive occurrences of last
+}
+
+// observe records the fingerprint of err and reports whether it has occurred m
+// times in a row.
+func (r *repeats) observe(err error) bool {
+	fp := r.print(err)
+	if fp == "" {
+		r.last, r.count = "", 0
+		return false
+	}
+	if fp == r.last {
+		r.count++
+	} else {
+		r.last, r.count = fp, 1
+	}
+	return r.count >= r.m
+}
@@ -0,0 +1,66 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestCycler_Fingerprint(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Fingerprint(func(err error) string { return err.Error() }, 3)
+
+	transient := errors.New("transient")
+	// the fingerprint changes twice before it repeats three times in a row
+	errs := []error{ErrTest, ErrTest, transient, ErrTest, ErrTest, ErrTest}
+
+	n := 0
+	err := cycler.Try(func(k int) error {
+		if n == len(errs) {
+			t.Fatalf("too many attempts")
+		}
+		n++
+		return errs[n-1]
+	})
+
+	if !errors.Is(err, retry.ErrLimitExceeded) || !errors.Is(err, ErrTest) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if n != len(errs) {
+		t.Errorf("got %d attempts, want %d", n, len(errs))
+	}
+}
+
+func TestCycler_Fingerprint_Empty(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Limit(5)
+	cycler.Fingerprint(func(err error) string { return "" }, 1)
+
+	n := 0
+	_ = cycler.Try(func(k int) error {
+		n = k
+		return ErrTest
+	})
+
+	if n != 5 {
+		t.Errorf("got %d attempts, want 5", n)
+	}
+}
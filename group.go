@@ -0,0 +1,112 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// A CycleGroup runs related retry cycles that are cancelled together as soon as
+// one of them is forced to exit with a fatal error (see [ForceExit]). This
+// suits workflows in which one unrecoverable failure renders the retries of
+// sibling cycles pointless. Use [NewCycleGroup] to create a new group.
+type CycleGroup struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	fatal  []error // errors that cancel the group; any if empty
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	err    error // fatal error that cancelled the group
+	first  error // first error returned by a member cycle
+}
+
+// NewCycleGroup creates a new [CycleGroup] whose cycles run within ctx. If no
+// fatal errors are given, any forced exit of a member cycle cancels the group.
+// Otherwise, only forced exits caused by an error that matches one of them
+// according to errors.Is do so.
+func NewCycleGroup(ctx context.Context, fatal ...error) *CycleGroup {
+	ctx, cancel := context.WithCancel(ctx)
+	return &CycleGroup{
+		ctx:    ctx,
+		cancel: cancel,
+		fatal:  fatal,
+	}
+}
+
+// Go runs attempt in a new retry cycle scheduled by c, in its own goroutine.
+// The cycle is cancelled once another member of the group exits fatally, in
+// which case it ends with [ContextCancelled].
+func (g *CycleGroup) Go(c *Cycler, attempt ContextAttemptFunc) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		err := c.Run(g.ctx, func(ctx context.Context, n int) error {
+			err := attempt(ctx, n)
+			if e, ok := err.(*ExitError); ok && g.fatalErr(e.Cause) {
+				g.abort(e.Cause)
+			}
+			return err
+		})
+		if err != nil {
+			g.mu.Lock()
+			if g.first == nil {
+				g.first = err
+			}
+			g.mu.Unlock()
+		}
+	}()
+}
+
+// fatalErr reports whether err cancels the group.
+func (g *CycleGroup) fatalErr(err error) bool {
+	if len(g.fatal) == 0 {
+		return true
+	}
+	for _, f := range g.fatal {
+		if errors.Is(err, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// abort cancels the group due to err, unless it was cancelled before.
+func (g *CycleGroup) abort(err error) {
+	g.mu.Lock()
+	if g.err == nil {
+		g.err = err
+	}
+	g.mu.Unlock()
+	g.cancel()
+}
+
+// Wait blocks until all member cycles have ended. It returns the fatal error
+// that cancelled the group, if any, or the first error returned by a member
+// cycle otherwise. It returns nil if all cycles succeeded. The group must not
+// be reused once Wait has returned.
+func (g *CycleGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.err != nil {
+		return g.err
+	}
+	return g.first
+}
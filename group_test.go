@@ -0,0 +1,110 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestCycleGroup(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Hour))
+
+	g := retry.NewCycleGroup(context.Background(), ErrFatal)
+	started := make(chan struct{})
+	cancelled := make(chan error, 1)
+	g.Go(cycler, func(ctx context.Context, n int) error {
+		close(started)
+		<-ctx.Done()
+		cancelled <- ctx.Err()
+		return ctx.Err()
+	})
+	<-started
+	g.Go(cycler, func(ctx context.Context, n int) error {
+		return retry.ForceExit(ErrFatal)
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- g.Wait() }()
+	select {
+	case err := <-done:
+		if err != ErrFatal {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("group was not cancelled")
+	}
+	if err := <-cancelled; err != context.Canceled {
+		t.Errorf("sibling saw error %v, want context.Canceled", err)
+	}
+}
+
+func TestCycleGroup_NotFatal(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Limit(3)
+
+	g := retry.NewCycleGroup(context.Background(), ErrFatal)
+	g.Go(cycler, func(ctx context.Context, n int) error {
+		return retry.ForceExit(ErrTest)
+	})
+	attempts := 0
+	g.Go(cycler, func(ctx context.Context, n int) error {
+		attempts = n
+		if n < 3 {
+			return ErrTest
+		}
+		return nil
+	})
+
+	if err := g.Wait(); !errors.Is(err, ErrTest) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestCycleGroup_AnyFatal(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+
+	g := retry.NewCycleGroup(context.Background())
+	g.Go(cycler, func(ctx context.Context, n int) error {
+		return retry.ForceExit(ErrTest)
+	})
+
+	if err := g.Wait(); err != ErrTest {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCycleGroup_Success(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+
+	g := retry.NewCycleGroup(context.Background())
+	for i := 0; i < 3; i++ {
+		g.Go(cycler, func(ctx context.Context, n int) error { return nil })
+	}
+
+	if err := g.Wait(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+// ErrKeyBudgetExhausted is returned by retry cycles guarded by a [KeyGuard]
+// once the attempts toward their key are used up for the current window.
+var ErrKeyBudgetExhausted = errors.New("retry: key budget exhausted")
+
+// A KeyGuard limits the total number of attempts toward the same key, such as
+// a user or a device, across all cycles within a window of time. This protects
+// downstream services from a single hot entity that keeps generating retries,
+// while other keys remain unaffected. It is safe for concurrent use. Use
+// [NewKeyGuard] to create a new guard.
+//
+// The Clock of a guard determines the current time. If nil, the system clock
+// is used. Cyclers consult the guard by means of their own Clock instead.
+type KeyGuard struct {
+	Clock  backoff.Clock
+	mu     sync.Mutex
+	max    int               // maximum number of attempts per window
+	window time.Duration     // length of a window
+	keys   map[string]*tally // attempts in the current window per key
+	sweep  time.Time         // time of the last sweep
+}
+
+// tally counts the attempts toward a key within a window.
+type tally struct {
+	start time.Time // start of the window
+	count int       // number of attempts
+}
+
+// NewKeyGuard creates a new [KeyGuard] that allows up to max attempts per key
+// within each window. A window starts with the first attempt toward a key. The
+// function panics if max < 1 or window <= 0.
+func NewKeyGuard(max int, window time.Duration) *KeyGuard {
+	if max < 1 {
+		panic(fmt.Sprintf("max = %d, must be >= 1", max))
+	}
+	if window <= 0 {
+		panic(fmt.Sprintf("window = %s, must be > 0", window))
+	}
+	return &KeyGuard{
+		max:    max,
+		window: window,
+		keys:   make(map[string]*tally),
+	}
+}
+
+// Allow reports whether another attempt toward key is within budget, and if
+// so, records it.
+func (g *KeyGuard) Allow(key string) bool {
+	return g.allow(g.clock().Time(), key)
+}
+
+// clock returns the Clock of g, or the system clock if there is none.
+func (g *KeyGuard) clock() backoff.Clock {
+	if g.Clock == nil {
+		return now
+	}
+	return g.Clock
+}
+
+// allow implements [KeyGuard.Allow] for an attempt at time now.
+func (g *KeyGuard) allow(now time.Time, key string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if now.Sub(g.sweep) >= g.window {
+		// forget keys whose windows have passed
+		for k, t := range g.keys {
+			if now.Sub(t.start) >= g.window {
+				delete(g.keys, k)
+			}
+		}
+		g.sweep = now
+	}
+	t, ok := g.keys[key]
+	if !ok || now.Sub(t.start) >= g.window {
+		t = &tally{start: now}
+		g.keys[key] = t
+	}
+	if t.count >= g.max {
+		return false
+	}
+	t.count++
+	return true
+}
+
+// Guard charges each attempt, including the initial one, against the budget
+// of g for the key derived from the context of the cycle by key. Once the
+// budget is exhausted, cycles toward that key end right away with
+// [ForcedExit], returning [ErrKeyBudgetExhausted], until the window has
+// passed. Sharing the same guard among many cyclers limits the aggregate
+// attempts toward a key. If g is nil, no guard will be applied.
+func (c *Cycler) Guard(g *KeyGuard, key KeyFunc) {
+	c.audit.touch()
+	if g == nil {
+		return
+	}
+	c.befores = append(c.befores, func(ctx context.Context, n int) error {
+		if !g.allow(c.Clock.Time(), key(ctx)) {
+			return ErrKeyBudgetExhausted
+		}
+		return nil
+	})
+}
@@ -0,0 +1,91 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestKeyGuard_Allow(t *testing.T) {
+	const D = 20 * time.Millisecond
+
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	g := retry.NewKeyGuard(2, D)
+	g.Clock = backoff.ClockFunc(func() time.Time { return now })
+	for i, exp := range []bool{true, true, false} {
+		if act := g.Allow("a"); act != exp {
+			t.Errorf("#%d: Allow() = %t, want %t", i, act, exp)
+		}
+	}
+	if !g.Allow("b") {
+		t.Error("other keys must not be affected")
+	}
+
+	now = now.Add(D)
+	if !g.Allow("a") {
+		t.Error("budget was not replenished after the window")
+	}
+}
+
+// userKey is the context key under which tests store the user.
+type userKey struct{}
+
+func TestCycler_Guard(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Limit(3)
+	cycler.Guard(retry.NewKeyGuard(4, 1*time.Hour), func(ctx context.Context) string {
+		return ctx.Value(userKey{}).(string)
+	})
+
+	hot := context.WithValue(context.Background(), userKey{}, "hot")
+	attempts := 0
+	attempt := func(n int) error {
+		attempts++
+		return ErrTest
+	}
+
+	// the first cycle uses 3 of 4 attempts
+	_ = cycler.TryWithContext(hot, attempt)
+	// the second cycle ends after a single attempt
+	err := cycler.TryWithContext(hot, attempt)
+	if err != retry.ErrKeyBudgetExhausted {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if attempts != 4 {
+		t.Errorf("attempts = %d, want 4", attempts)
+	}
+
+	cold := context.WithValue(context.Background(), userKey{}, "cold")
+	if err := cycler.TryWithContext(cold, func(n int) error { return nil }); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestNewKeyGuard_Invalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	retry.NewKeyGuard(0, time.Second)
+}
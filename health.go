@@ -0,0 +1,96 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// A CheckFunc probes the health of a dependency. It returns nil if the
+// dependency is healthy.
+type CheckFunc func(ctx context.Context) error
+
+// A TransitionFunc is invoked when the health state of a dependency changes.
+// If healthy is false, err is the error returned by the failing check.
+type TransitionFunc func(healthy bool, err error)
+
+// Healthy polls check in a retry cycle scheduled by c until it succeeds, which
+// is useful for waiting on dependencies at startup. It returns the error of the
+// retry cycle, which is nil once the dependency is healthy. Each check receives
+// the context of the current attempt, see [Cycler.Run].
+func Healthy(ctx context.Context, c *Cycler, check CheckFunc) error {
+	return c.Run(ctx, func(ctx context.Context, _ int) error {
+		return check(ctx)
+	})
+}
+
+// Monitor keeps polling check until ctx is cancelled, and reports each change
+// of the health state to transition, including the initial state. While the
+// dependency is healthy, check is executed once per interval. As soon as it
+// fails, the dependency is polled in retry cycles scheduled by c, just like
+// with [Healthy]. If such a cycle gives up, the next one starts after interval
+// has passed. Waiting is performed by the Sleeper of c. Monitor blocks until
+// ctx is cancelled, and then returns the error of ctx.
+func Monitor(
+	ctx context.Context,
+	c *Cycler,
+	interval time.Duration,
+	check CheckFunc,
+	transition TransitionFunc,
+) error {
+	sleeper := c.Sleeper
+	if sleeper == nil {
+		sleeper = ClockSleeper(c.Clock)
+	}
+
+	known := false   // whether the state is known
+	healthy := false // current state
+	report := func(ok bool, err error) {
+		if !known || ok != healthy {
+			known = true
+			healthy = ok
+			transition(ok, err)
+		}
+	}
+
+	for {
+		var err error
+		if healthy {
+			err = check(ctx)
+		} else {
+			err = Healthy(ctx, c, func(ctx context.Context) error {
+				err := check(ctx)
+				if err != nil {
+					report(false, err)
+				}
+				return err
+			})
+		}
+		if e := ctx.Err(); e != nil {
+			return e
+		}
+		if err == nil {
+			report(true, nil)
+		} else {
+			report(false, err)
+		}
+		if err := sleeper.Sleep(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
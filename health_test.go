@@ -0,0 +1,87 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestHealthy(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+
+	calls := 0
+	err := retry.Healthy(context.Background(), cycler, func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return ErrTest
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("got %d calls, want 3", calls)
+	}
+}
+
+func TestMonitor(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Limit(2)
+	cycler.Sleeper = retry.SleeperFunc(func(ctx context.Context, d time.Duration) error {
+		return ctx.Err()
+	})
+
+	// outcomes of consecutive checks; the monitor is stopped afterwards
+	outcomes := []error{ErrTest, nil, nil, ErrTest, ErrTest, ErrTest, nil}
+	calls := 0
+	check := func(ctx context.Context) error {
+		if calls == len(outcomes) {
+			cancel()
+			return nil
+		}
+		err := outcomes[calls]
+		calls++
+		return err
+	}
+
+	var act []bool
+	err := retry.Monitor(ctx, cycler, time.Second, check, func(healthy bool, err error) {
+		if healthy != (err == nil) {
+			t.Errorf("unexpected error: %v", err)
+		}
+		act = append(act, healthy)
+	})
+
+	if err != context.Canceled {
+		t.Errorf("unexpected error: %#v", err)
+	}
+	exp := []bool{false, true, false, true}
+	if !reflect.DeepEqual(act, exp) {
+		t.Errorf("transitions were %v, want %v", act, exp)
+	}
+}
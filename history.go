@@ -0,0 +1,142 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// A Failure describes a failed attempt within a retry cycle.
+type Failure struct {
+	Attempt  int           // attempt count, starting at 1
+	Time     time.Time     // time at which the attempt started
+	Duration time.Duration // time it took to execute the attempt
+	Delay    time.Duration // delay that followed, or 0 if there was no retry
+	Err      error         // error returned by the attempt
+}
+
+// MarshalJSON encodes f as a JSON object. The error is represented by its
+// message, and the delay is omitted if no retry followed.
+func (f Failure) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Attempt  int       `json:"attempt"`
+		Time     time.Time `json:"time"`
+		Duration string    `json:"duration"`
+		Delay    string    `json:"delay,omitempty"`
+		Error    string    `json:"error"`
+	}{
+		Attempt:  f.Attempt,
+		Time:     f.Time,
+		Duration: f.Duration.String(),
+		Error:    f.Err.Error(),
+	}
+	if f.Delay > 0 {
+		v.Delay = f.Delay.String()
+	}
+	return json.Marshal(v)
+}
+
+// ErrLimitExceeded matches any [CycleError] of a retry cycle that gave up
+// because the maximum number of attempts was reached.
+var ErrLimitExceeded = errors.New("retry: limit exceeded")
+
+// ErrTimeout matches any [CycleError] of a retry cycle that gave up because it
+// timed out.
+var ErrTimeout = errors.New("retry: timed out")
+
+// A CycleError is returned by a retry cycle that gave up after exceeding some
+// limit. It wraps the error returned by the last attempt, and additionally
+// carries the reason why the cycle gave up. Hence, both errors.Is(err, cause)
+// and errors.Is(err, [ErrLimitExceeded]) or errors.Is(err, [ErrTimeout]) hold
+// for the same error. If [Cycler.History] is enabled, the most recent failures
+// are included as well.
+type CycleError struct {
+	Cause   error      // error returned by the last attempt
+	Reason  StopReason // either LimitReached or TimedOut
+	History []Failure  // most recent failures, oldest first
+	Seed    int64      // seed used for jitter, see [Cycler.Seed]
+}
+
+func (e *CycleError) Error() string { return e.Cause.Error() }
+
+func (e *CycleError) Unwrap() error { return e.Cause }
+
+// Is reports whether target is the sentinel error corresponding to the reason
+// of e, that is, [ErrLimitExceeded] or [ErrTimeout].
+func (e *CycleError) Is(target error) bool {
+	switch target {
+	case ErrLimitExceeded:
+		return e.Reason == LimitReached
+	case ErrTimeout:
+		return e.Reason == TimedOut
+	default:
+		return false
+	}
+}
+
+// MarshalJSON encodes e as a JSON object holding the error message, the
+// reason, the seed, and the timeline of failures, such that it can be attached
+// to failure reports.
+func (e *CycleError) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Error   string    `json:"error"`
+		Reason  string    `json:"reason,omitempty"`
+		Seed    int64     `json:"seed"`
+		History []Failure `json:"history"`
+	}{
+		Error:   e.Error(),
+		Seed:    e.Seed,
+		History: e.History,
+	}
+	if e.Reason != Succeeded {
+		v.Reason = e.Reason.String()
+	}
+	return json.Marshal(v)
+}
+
+// ring is a fixed-size buffer that keeps the most recent failures.
+type ring struct {
+	buf  []Failure
+	next int  // index of the next write
+	full bool // whether buf has wrapped around
+}
+
+func newRing(k int) *ring {
+	return &ring{buf: make([]Failure, k)}
+}
+
+// push adds f to the buffer, evicting the oldest failure if needed.
+func (r *ring) push(f Failure) {
+	r.buf[r.next] = f
+	r.next++
+	if r.next == len(r.buf) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// slice returns the buffered failures, oldest first.
+func (r *ring) slice() []Failure {
+	if !r.full {
+		return append([]Failure(nil), r.buf[:r.next]...)
+	}
+	s := make([]Failure, 0, len(r.buf))
+	s = append(s, r.buf[r.next:]...)
+	return append(s, r.buf[:r.next]...)
+}
@@ -0,0 +1,174 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestCycler_History(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Limit(5)
+	cycler.History(3)
+
+	err := cycler.Try(func(n int) error {
+		return fmt.Errorf("#%d: %w", n, ErrTest)
+	})
+
+	var e *retry.CycleError
+	if !errors.As(err, &e) {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	if !errors.Is(err, ErrTest) {
+		t.Errorf("error does not wrap the last error")
+	}
+
+	if len(e.History) != 3 {
+		t.Fatalf("len(history) = %d, want 3", len(e.History))
+	}
+
+	for i, f := range e.History {
+		exp := i + 3
+		if f.Attempt != exp {
+			t.Errorf("history[%d].Attempt = %d, want %d", i, f.Attempt, exp)
+		}
+		if msg := fmt.Sprintf("#%d: test", exp); f.Err.Error() != msg {
+			t.Errorf("history[%d].Err = %q, want %q", i, f.Err, msg)
+		}
+	}
+}
+
+func TestCycler_History_Short(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Limit(2)
+	cycler.History(3)
+
+	err := cycler.Try(func(n int) error { return ErrTest })
+
+	var e *retry.CycleError
+	if !errors.As(err, &e) {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	if len(e.History) != 2 {
+		t.Errorf("len(history) = %d, want 2", len(e.History))
+	}
+}
+
+func TestCycleError_MarshalJSON(t *testing.T) {
+	d := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	err := &retry.CycleError{
+		Cause: ErrTest,
+		Seed:  42,
+		History: []retry.Failure{
+			{Attempt: 1, Time: d, Duration: 5 * time.Millisecond, Delay: 1 * time.Second, Err: ErrTest},
+			{Attempt: 2, Time: d.Add(time.Second), Err: ErrTest},
+		},
+	}
+
+	act, e := json.Marshal(err)
+	if e != nil {
+		t.Fatalf("unexpected error: %v", e)
+	}
+
+	const exp = `{"error":"test","seed":42,"history":[` +
+		`{"attempt":1,"time":"2022-01-01T00:00:00Z","duration":"5ms","delay":"1s","error":"test"},` +
+		`{"attempt":2,"time":"2022-01-01T00:00:01Z","duration":"0s","error":"test"}]}`
+
+	if string(act) != exp {
+		t.Errorf("json was %s, want %s", act, exp)
+	}
+}
+
+func TestCycler_History_Delay(t *testing.T) {
+	const D = 1 * time.Millisecond
+
+	cycler := retry.NewCycler(backoff.Constant(D))
+	cycler.Limit(2)
+	cycler.History(2)
+
+	err := cycler.Try(func(n int) error { return ErrTest })
+
+	var e *retry.CycleError
+	if !errors.As(err, &e) {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	if d := e.History[0].Delay; d != D {
+		t.Errorf("history[0].Delay = %s, want %s", d, D)
+	}
+
+	if d := e.History[1].Delay; d != 0 {
+		t.Errorf("history[1].Delay = %s, want 0", d)
+	}
+
+	if !e.History[0].Time.Before(e.History[1].Time) {
+		t.Errorf("history is not in chronological order")
+	}
+}
+
+func TestCycleError_Is(t *testing.T) {
+	cause := fmt.Errorf("wrapped: %w", io.ErrUnexpectedEOF)
+
+	for i, test := range []struct {
+		cycler  func() *retry.Cycler
+		reason  error
+		unknown error
+	}{
+		{func() *retry.Cycler {
+			c := retry.NewCycler(backoff.Constant(0))
+			c.Limit(2)
+			return c
+		}, retry.ErrLimitExceeded, retry.ErrTimeout},
+		{func() *retry.Cycler {
+			c := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+			c.WaitTimeout(1 * time.Millisecond)
+			return c
+		}, retry.ErrTimeout, retry.ErrLimitExceeded},
+	} {
+		err := test.cycler().Try(func(n int) error { return cause })
+
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("#%d: error does not match cause: %v", i, err)
+		}
+		if !errors.Is(err, test.reason) {
+			t.Errorf("#%d: error does not match %v", i, test.reason)
+		}
+		if errors.Is(err, test.unknown) {
+			t.Errorf("#%d: error unexpectedly matches %v", i, test.unknown)
+		}
+	}
+}
+
+func TestCycler_ExitError_NotWrapped(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+
+	err := cycler.Try(func(n int) error { return retry.ForceExit(ErrTest) })
+
+	if err != ErrTest {
+		t.Errorf("unexpected error: %#v", err)
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// An Instrument observes the timing of retry cycles. Register an instrument
+// with [Cycler.Instrument]. Implementations must be safe for concurrent use, as
+// the same cycler may run multiple retry cycles at once.
+type Instrument interface {
+	// ObserveAttempt is called after the n-th attempt of a retry cycle has
+	// completed. The duration d is the time it took to execute the attempt, and
+	// err is the error it returned.
+	ObserveAttempt(n int, d time.Duration, err error)
+	// ObserveDelay is called with the delay d computed after the n-th attempt
+	// failed, right before the cycle starts waiting for the next retry.
+	ObserveDelay(n int, d time.Duration)
+}
+
+// DefaultBounds are the bucket bounds used by [NewHistogram] if no bounds are
+// given.
+var DefaultBounds = []time.Duration{
+	1 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	1 * time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	1 * time.Minute,
+}
+
+// A Bucket of a [Histogram] counts the observations that fall in the half-open
+// interval between the upper bound of the previous bucket and Le.
+type Bucket struct {
+	Le    time.Duration // inclusive upper bound
+	Count uint64        // number of observations
+}
+
+// A Histogram counts observed durations in buckets. It is safe for concurrent
+// use. Use [NewHistogram] to create a new histogram.
+type Histogram struct {
+	mu     sync.Mutex
+	bounds []time.Duration // ascending upper bounds
+	counts []uint64        // observations per bucket, plus overflow
+	sum    time.Duration   // sum of all observations
+}
+
+// NewHistogram creates a new [Histogram] with the given bucket bounds. An
+// additional bucket collects observations exceeding the largest bound. If no
+// bounds are given, [DefaultBounds] are used.
+func NewHistogram(bounds ...time.Duration) *Histogram {
+	if len(bounds) == 0 {
+		bounds = DefaultBounds
+	}
+	b := append([]time.Duration(nil), bounds...)
+	sort.Slice(b, func(i, j int) bool { return b[i] < b[j] })
+	return &Histogram{
+		bounds: b,
+		counts: make([]uint64, len(b)+1),
+	}
+}
+
+// Observe adds d to the histogram.
+func (h *Histogram) Observe(d time.Duration) {
+	i := sort.Search(len(h.bounds), func(i int) bool {
+		return d <= h.bounds[i]
+	})
+	h.mu.Lock()
+	h.counts[i]++
+	h.sum += d
+	h.mu.Unlock()
+}
+
+// Buckets returns a snapshot of the buckets in ascending order. The upper bound
+// of the last bucket is the maximum representable duration.
+func (h *Histogram) Buckets() []Bucket {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	buckets := make([]Bucket, len(h.counts))
+	for i, n := range h.counts {
+		le := time.Duration(math.MaxInt64)
+		if i < len(h.bounds) {
+			le = h.bounds[i]
+		}
+		buckets[i] = Bucket{Le: le, Count: n}
+	}
+	return buckets
+}
+
+// Count returns the total number of observations.
+func (h *Histogram) Count() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var total uint64
+	for _, n := range h.counts {
+		total += n
+	}
+	return total
+}
+
+// Sum returns the sum of all observations.
+func (h *Histogram) Sum() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.sum
+}
+
+// A Recorder is a simple [Instrument] that records the distributions of
+// attempt durations and backoff delays in a [Histogram] each.
+type Recorder struct {
+	Attempts *Histogram // durations of attempts
+	Delays   *Histogram // computed backoff delays
+}
+
+// NewRecorder creates a new [Recorder] whose histograms use the given bucket
+// bounds. If no bounds are given, [DefaultBounds] are used.
+func NewRecorder(bounds ...time.Duration) *Recorder {
+	return &Recorder{
+		Attempts: NewHistogram(bounds...),
+		Delays:   NewHistogram(bounds...),
+	}
+}
+
+func (r *Recorder) ObserveAttempt(n int, d time.Duration, err error) {
+	r.Attempts.Observe(d)
+}
+
+func (r *Recorder) ObserveDelay(n int, d time.Duration) {
+	r.Delays.Observe(d)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestHistogram(t *testing.T) {
+	h := retry.NewHistogram(10*time.Millisecond, 1*time.Millisecond)
+	h.Observe(1 * time.Millisecond)
+	h.Observe(5 * time.Millisecond)
+	h.Observe(7 * time.Millisecond)
+	h.Observe(1 * time.Second)
+
+	buckets := h.Buckets()
+	for i, exp := range []uint64{1, 2, 1} {
+		if act := buckets[i].Count; act != exp {
+			t.Errorf("count of bucket #%d was %d, want %d", i, act, exp)
+		}
+	}
+
+	if act, exp := h.Count(), uint64(4); act != exp {
+		t.Errorf("count was %d, want %d", act, exp)
+	}
+
+	if act, exp := h.Sum(), 1013*time.Millisecond; act != exp {
+		t.Errorf("sum was %s, want %s", act, exp)
+	}
+}
+
+func TestCycler_Instrument(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Limit(3)
+
+	r := retry.NewRecorder()
+	cycler.Instrument(r)
+
+	_ = cycler.Try(func(n int) error { return ErrTest })
+
+	if act, exp := r.Attempts.Count(), uint64(3); act != exp {
+		t.Errorf("attempts observed: %d, want %d", act, exp)
+	}
+
+	if act, exp := r.Delays.Count(), uint64(2); act != exp {
+		t.Errorf("delays observed: %d, want %d", act, exp)
+	}
+
+	if act, exp := r.Delays.Sum(), 2*time.Millisecond; act != exp {
+		t.Errorf("sum of delays was %s, want %s", act, exp)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// A JSONLogger writes one JSON object per lifecycle event of the retry cycles
+// scheduled by the cyclers it is attached to. Each object is written on a line
+// of its own and carries the time and type of the event, the name and labels
+// of the cycler if set, and the attempt count. Depending on the event, the
+// following types are emitted:
+//
+//   - "attempt_failed" along with the duration and error of the attempt,
+//   - "sleeping" along with the delay until the next attempt,
+//   - "succeeded" once an attempt completes successfully, and
+//   - "gave_up" along with the reason and final error otherwise.
+//
+// A logger is safe for concurrent use, such that it can be shared among many
+// cyclers. Errors occurring while writing are dropped.
+type JSONLogger struct {
+	mu  sync.Mutex // guards enc
+	enc *json.Encoder
+}
+
+// NewJSONLogger creates a new [JSONLogger] that writes to w.
+func NewJSONLogger(w io.Writer) *JSONLogger {
+	return &JSONLogger{enc: json.NewEncoder(w)}
+}
+
+// event is the JSON representation of a lifecycle event.
+type event struct {
+	Time     time.Time `json:"time"`
+	Event    string    `json:"event"`
+	Name     string    `json:"name,omitempty"`
+	Labels   Labels    `json:"labels,omitempty"`
+	Attempt  int       `json:"attempt"`
+	Duration string    `json:"duration,omitempty"`
+	Delay    string    `json:"delay,omitempty"`
+	Reason   string    `json:"reason,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Attach registers the logger with c.
+func (l *JSONLogger) Attach(c *Cycler) {
+	c.OnFailure(func(f Failure) {
+		l.log(event{
+			Time:     c.Clock.Time(),
+			Event:    "attempt_failed",
+			Name:     c.Name,
+			Labels:   c.Labels,
+			Attempt:  f.Attempt,
+			Duration: f.Duration.String(),
+			Error:    f.Err.Error(),
+		})
+	})
+	c.OnError(func(n int, delay time.Duration, err error) {
+		l.log(event{
+			Time:    c.Clock.Time(),
+			Event:   "sleeping",
+			Name:    c.Name,
+			Labels:  c.Labels,
+			Attempt: n,
+			Delay:   delay.String(),
+		})
+	})
+	c.OnExit(func(reason StopReason, n int, err error) {
+		e := event{
+			Time:    c.Clock.Time(),
+			Event:   "succeeded",
+			Name:    c.Name,
+			Labels:  c.Labels,
+			Attempt: n,
+		}
+		if reason != Succeeded {
+			e.Event = "gave_up"
+			e.Reason = reason.String()
+			if err != nil {
+				e.Error = err.Error()
+			}
+		}
+		l.log(e)
+	})
+}
+
+func (l *JSONLogger) log(e event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_ = l.enc.Encode(e)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestJSONLogger(t *testing.T) {
+	var buf bytes.Buffer
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Name = "test"
+	cycler.Limit(2)
+	retry.NewJSONLogger(&buf).Attach(cycler)
+
+	_ = cycler.Try(func(n int) error { return ErrTest })
+	_ = cycler.Try(func(n int) error { return nil })
+
+	var act []string
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var e map[string]interface{}
+		if err := dec.Decode(&e); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if e["name"] != "test" {
+			t.Errorf("name was %v, want %q", e["name"], "test")
+		}
+		act = append(act, e["event"].(string))
+	}
+
+	exp := []string{
+		"attempt_failed",
+		"sleeping",
+		"attempt_failed",
+		"gave_up",
+		"succeeded",
+	}
+	if !reflect.DeepEqual(act, exp) {
+		t.Errorf("events were %q, want %q", act, exp)
+	}
+}
+
+func TestJSONLogger_GaveUp(t *testing.T) {
+	var buf bytes.Buffer
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Clock = backoff.ClockFunc(func() time.Time { return time.Unix(0, 0).UTC() })
+	retry.NewJSONLogger(&buf).Attach(cycler)
+
+	_ = cycler.Try(func(n int) error { return retry.ForceExit(ErrTest) })
+
+	act := buf.String()
+	exp := `{"time":"1970-01-01T00:00:00Z","event":"gave_up","attempt":1,` +
+		`"reason":"forced exit","error":"test"}` + "\n"
+	if act != exp {
+		t.Errorf("log was %s, want %s", act, exp)
+	}
+}
+
+func TestJSONLogger_Labels(t *testing.T) {
+	var buf bytes.Buffer
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Labels = retry.Labels{"team": "core"}
+	retry.NewJSONLogger(&buf).Attach(cycler)
+
+	_ = cycler.Try(func(n int) error { return nil })
+
+	var e struct {
+		Labels map[string]string `json:"labels"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &e); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Labels["team"] != "core" {
+		t.Errorf("labels were %v", e.Labels)
+	}
+}
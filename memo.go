@@ -0,0 +1,121 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// A ValueFunc is a [ContextAttemptFunc] that produces a value of type T.
+type ValueFunc[T any] func(ctx context.Context, n int) (T, error)
+
+// entry is a cached result.
+type entry[T any] struct {
+	value   T
+	expires time.Time
+}
+
+// A Memo caches the results of keyed operations that recently succeeded. Within
+// the time to live, [Memo.Run] returns the cached result immediately instead of
+// scheduling a new retry cycle, which suits read-heavy operations. Failures are
+// never cached. A memo is safe for concurrent use; concurrent calls for the
+// same uncached key each run their own cycle. Use [NewMemo] to create a new
+// memo.
+type Memo[T any] struct {
+	cycler  *Cycler
+	ttl     time.Duration
+	mu      sync.Mutex // guards the fields below
+	entries map[string]entry[T]
+	swept   time.Time // time of the last sweep
+}
+
+// NewMemo creates a new [Memo] whose retry cycles are scheduled by c, and
+// whose results expire after ttl, as measured by the Clock of c. The function
+// panics if ttl <= 0.
+func NewMemo[T any](c *Cycler, ttl time.Duration) *Memo[T] {
+	if ttl <= 0 {
+		panic(fmt.Sprintf("ttl = %s, must be > 0", ttl))
+	}
+	return &Memo[T]{
+		cycler:  c,
+		ttl:     ttl,
+		entries: make(map[string]entry[T]),
+	}
+}
+
+// Run returns the cached result for key if it has not expired yet. Otherwise,
+// it retries fn using [Cycler.Run], and caches the result if the cycle
+// succeeds.
+func (m *Memo[T]) Run(
+	ctx context.Context,
+	key string,
+	fn ValueFunc[T],
+) (T, error) {
+	if v, ok := m.get(key); ok {
+		return v, nil
+	}
+	var v T
+	err := m.cycler.Run(ctx, func(ctx context.Context, n int) error {
+		var err error
+		v, err = fn(ctx, n)
+		return err
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	m.put(key, v)
+	return v, nil
+}
+
+// Forget removes the cached result for key, if any.
+func (m *Memo[T]) Forget(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+}
+
+func (m *Memo[T]) get(key string) (T, bool) {
+	now := m.cycler.Clock.Time()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok || !now.Before(e.expires) {
+		var zero T
+		return zero, false
+	}
+	return e.value, true
+}
+
+func (m *Memo[T]) put(key string, v T) {
+	now := m.cycler.Clock.Time()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if now.Sub(m.swept) >= m.ttl {
+		// evict expired entries, at most once per ttl
+		m.swept = now
+		for k, e := range m.entries {
+			if !now.Before(e.expires) {
+				delete(m.entries, k)
+			}
+		}
+	}
+	m.entries[key] = entry[T]{value: v, expires: now.Add(m.ttl)}
+}
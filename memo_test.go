@@ -0,0 +1,94 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestMemo(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Clock = backoff.ClockFunc(func() time.Time { return now })
+
+	m := retry.NewMemo[int](cycler, time.Minute)
+
+	calls := 0
+	fn := func(ctx context.Context, n int) (int, error) {
+		calls++
+		if n < 2 {
+			return 0, ErrTest
+		}
+		return calls, nil
+	}
+
+	for i, exp := range []struct {
+		value int
+		calls int
+	}{
+		{2, 2}, // executed with one retry
+		{2, 2}, // cached
+	} {
+		v, err := m.Run(context.Background(), "key", fn)
+		if err != nil {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+		if v != exp.value || calls != exp.calls {
+			t.Errorf("#%d: got (%d, %d calls), want (%d, %d calls)",
+				i, v, calls, exp.value, exp.calls)
+		}
+	}
+
+	// other keys are not affected
+	if v, _ := m.Run(context.Background(), "other", fn); v != 4 {
+		t.Errorf("value was %d, want 4", v)
+	}
+
+	// results expire
+	now = now.Add(time.Minute)
+	if v, _ := m.Run(context.Background(), "key", fn); v != 6 {
+		t.Errorf("value was %d, want 6", v)
+	}
+
+	m.Forget("key")
+	if v, _ := m.Run(context.Background(), "key", fn); v != 8 {
+		t.Errorf("value was %d, want 8", v)
+	}
+}
+
+func TestMemo_Failure(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Limit(1)
+	m := retry.NewMemo[string](cycler, time.Hour)
+
+	calls := 0
+	fn := func(ctx context.Context, n int) (string, error) {
+		calls++
+		return "", ErrTest
+	}
+	_, _ = m.Run(context.Background(), "key", fn)
+	_, _ = m.Run(context.Background(), "key", fn)
+
+	if calls != 2 {
+		t.Errorf("got %d calls, want 2", calls)
+	}
+}
@@ -0,0 +1,51 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// cycles counts the retry cycles started in this process.
+var cycles uint64
+
+// nextID returns a new process-wide unique cycle ID.
+func nextID() uint64 {
+	return atomic.AddUint64(&cycles, 1)
+}
+
+// Metadata describes the attempt a context belongs to.
+type Metadata struct {
+	Cycle   uint64 // process-wide unique ID of the retry cycle
+	Attempt int    // attempt count, starting at 1
+	Policy  string // name of the cycler, see [Cycler.Name]
+	Target  string // target of the attempt, see [Cycler.Rotate]
+}
+
+// metadataKey is the context key under which [Metadata] is stored.
+type metadataKey struct{}
+
+// MetadataFrom extracts the [Metadata] from the context passed to an attempt
+// scheduled with [Cycler.Run]. Downstream code can use it to correlate retried
+// requests with their originating cycle, e.g. by recording it as span
+// attributes or propagating it as tracing baggage. The second return value is
+// false if ctx carries no metadata.
+func MetadataFrom(ctx context.Context) (Metadata, bool) {
+	m, ok := ctx.Value(metadataKey{}).(Metadata)
+	return m, ok
+}
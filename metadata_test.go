@@ -0,0 +1,70 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestMetadataFrom(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Name = "test"
+
+	var ms []retry.Metadata
+	attempt := func(ctx context.Context, n int) error {
+		m, ok := retry.MetadataFrom(ctx)
+		if !ok {
+			t.Fatalf("context carries no metadata")
+		}
+		ms = append(ms, m)
+		if n < 2 {
+			return ErrTest
+		}
+		return nil
+	}
+
+	_ = cycler.Run(context.Background(), attempt)
+	_ = cycler.Run(context.Background(), attempt)
+
+	if len(ms) != 4 {
+		t.Fatalf("got %d attempts, want 4", len(ms))
+	}
+	for i, m := range ms {
+		if exp := i%2 + 1; m.Attempt != exp {
+			t.Errorf("#%d: attempt was %d, want %d", i, m.Attempt, exp)
+		}
+		if m.Policy != "test" {
+			t.Errorf("#%d: policy was %q, want %q", i, m.Policy, "test")
+		}
+	}
+	if ms[0].Cycle != ms[1].Cycle || ms[2].Cycle != ms[3].Cycle {
+		t.Errorf("cycle IDs differ within a cycle")
+	}
+	if ms[0].Cycle == ms[2].Cycle {
+		t.Errorf("cycle IDs are not unique")
+	}
+}
+
+func TestMetadataFrom_None(t *testing.T) {
+	if _, ok := retry.MetadataFrom(context.Background()); ok {
+		t.Errorf("unexpected metadata")
+	}
+}
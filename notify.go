@@ -0,0 +1,113 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// A Report describes a retry cycle that gave up after exceeding some limit.
+type Report struct {
+	Name     string        // name of the policy, see [Cycler]
+	Labels   Labels        // labels of the policy, see [Cycler]
+	Reason   StopReason    // either LimitReached or TimedOut
+	Attempts int           // number of attempts made
+	Elapsed  time.Duration // time elapsed since the start of the cycle
+	Err      error         // error returned by the last attempt
+}
+
+// Chain returns the messages of all errors in the chain of r.Err, starting
+// with the outermost one.
+func (r Report) Chain() []string {
+	var chain []string
+	for err := r.Err; err != nil; err = errors.Unwrap(err) {
+		chain = append(chain, err.Error())
+	}
+	return chain
+}
+
+// MarshalJSON encodes r as a JSON object. The error is represented by the
+// messages of its chain.
+func (r Report) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name     string   `json:"name,omitempty"`
+		Labels   Labels   `json:"labels,omitempty"`
+		Reason   string   `json:"reason"`
+		Attempts int      `json:"attempts"`
+		Elapsed  string   `json:"elapsed"`
+		Errors   []string `json:"errors"`
+	}{
+		Name:     r.Name,
+		Labels:   r.Labels,
+		Reason:   r.Reason.String(),
+		Attempts: r.Attempts,
+		Elapsed:  r.Elapsed.String(),
+		Errors:   r.Chain(),
+	})
+}
+
+// A Notifier is alerted whenever a retry cycle gives up. See [Cycler.Notify].
+type Notifier interface {
+	// Notify delivers the report r. Notifiers are invoked synchronously before
+	// the cycle returns, so they should not block for long. Any error returned
+	// is dropped by the cycler.
+	Notify(r Report) error
+}
+
+// NotifierFunc is an adapter to allow the use of ordinary functions as
+// a [Notifier].
+type NotifierFunc func(r Report) error
+
+// Notify calls f(r).
+func (f NotifierFunc) Notify(r Report) error { return f(r) }
+
+// client is the default HTTP client of a [Webhook].
+var client = &http.Client{Timeout: 10 * time.Second}
+
+// A Webhook is a [Notifier] that posts reports as JSON to the specified URL.
+// If Client is nil, a default client with a timeout of 10 seconds is used.
+type Webhook struct {
+	URL    string       // endpoint that receives the reports
+	Client *http.Client // used to send the requests
+}
+
+// Notify posts r to the webhook URL. Any response status other than 2xx is
+// reported as an error.
+func (w *Webhook) Notify(r Report) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	c := w.Client
+	if c == nil {
+		c = client
+	}
+	res, err := c.Post(w.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("retry: webhook responded with status %s", res.Status)
+	}
+	return nil
+}
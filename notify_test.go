@@ -0,0 +1,118 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestCycler_Notify(t *testing.T) {
+	var reports []retry.Report
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Name = "test"
+	cycler.Limit(3)
+	cycler.Notify(retry.NotifierFunc(func(r retry.Report) error {
+		reports = append(reports, r)
+		return nil
+	}))
+
+	_ = cycler.Try(func(n int) error { return nil })
+	_ = cycler.Try(func(n int) error { return retry.ForceExit(ErrTest) })
+	if len(reports) != 0 {
+		t.Fatalf("unexpected reports: %v", reports)
+	}
+
+	_ = cycler.Try(func(n int) error { return ErrTest })
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports, want 1", len(reports))
+	}
+	r := reports[0]
+	if r.Name != "test" || r.Reason != retry.LimitReached ||
+		r.Attempts != 3 || r.Err != ErrTest || r.Elapsed <= 0 {
+		t.Errorf("unexpected report: %+v", r)
+	}
+}
+
+func TestReport_Chain(t *testing.T) {
+	r := retry.Report{Err: fmt.Errorf("outer: %w", ErrTest)}
+
+	act := r.Chain()
+	exp := []string{"outer: test", "test"}
+
+	if !reflect.DeepEqual(act, exp) {
+		t.Errorf("chain was %q, want %q", act, exp)
+	}
+}
+
+func TestWebhook_Notify(t *testing.T) {
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		},
+	))
+	defer srv.Close()
+
+	w := &retry.Webhook{URL: srv.URL}
+	err := w.Notify(retry.Report{
+		Name:     "test",
+		Reason:   retry.TimedOut,
+		Attempts: 2,
+		Elapsed:  1 * time.Second,
+		Err:      ErrTest,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	exp := map[string]interface{}{
+		"name":     "test",
+		"reason":   "timed out",
+		"attempts": 2.0,
+		"elapsed":  "1s",
+		"errors":   []interface{}{"test"},
+	}
+	if !reflect.DeepEqual(body, exp) {
+		t.Errorf("body was %v, want %v", body, exp)
+	}
+}
+
+func TestWebhook_Notify_Status(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		},
+	))
+	defer srv.Close()
+
+	w := &retry.Webhook{URL: srv.URL, Client: srv.Client()}
+	if err := w.Notify(retry.Report{Err: ErrTest}); err == nil {
+		t.Errorf("expected error")
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+// A Pacer spaces out events that target the same key, such that they are at
+// least a fixed gap apart. Unlike a [Throttle], which rejects or delays events
+// beyond a fixed rate, a pacer hands out consecutive time slots, smoothing the
+// aggregate load on a shared resource. It is safe for concurrent use. Use
+// [NewPacer] to create a new pacer.
+//
+// The Clock of a pacer determines the current time. If nil, the system clock
+// is used. A Clock that also implements [backoff.Timer] controls how long
+// [Pacer.Wait] blocks. Cyclers consult the pacer by means of their own Clock
+// and Sleeper instead.
+type Pacer struct {
+	Clock backoff.Clock
+	mu    sync.Mutex
+	gap   time.Duration        // minimum time between events
+	next  map[string]time.Time // next free slot per key
+	sweep time.Time            // time of the last sweep
+}
+
+// NewPacer creates a new [Pacer] that spaces events with the same key at least
+// gap apart. The function panics if gap <= 0.
+func NewPacer(gap time.Duration) *Pacer {
+	if gap <= 0 {
+		panic(fmt.Sprintf("gap = %s, must be > 0", gap))
+	}
+	return &Pacer{
+		gap:  gap,
+		next: make(map[string]time.Time),
+	}
+}
+
+// clock returns the Clock of p, or the system clock if there is none.
+func (p *Pacer) clock() backoff.Clock {
+	if p.Clock == nil {
+		return now
+	}
+	return p.Clock
+}
+
+// reserve books the next free slot for key at time now, and returns the time
+// left until that slot begins.
+func (p *Pacer) reserve(now time.Time, key string) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if now.Sub(p.sweep) >= p.gap {
+		// forget keys whose slots have passed
+		for k, t := range p.next {
+			if !t.After(now) {
+				delete(p.next, k)
+			}
+		}
+		p.sweep = now
+	}
+	slot := now
+	if t, ok := p.next[key]; ok && t.After(now) {
+		slot = t
+	}
+	p.next[key] = slot.Add(p.gap)
+	return slot.Sub(now)
+}
+
+// Wait books the next free slot for key, and blocks until it begins. It
+// returns early with the error of ctx if ctx is cancelled in the meantime, in
+// which case the slot is lost.
+func (p *Pacer) Wait(ctx context.Context, key string) error {
+	clock := p.clock()
+	wait := p.reserve(clock.Time(), key)
+	if wait <= 0 {
+		return nil
+	}
+	return ClockSleeper(clock).Sleep(ctx, wait)
+}
+
+// A KeyFunc derives a key from the context of a retry cycle, such as the
+// downstream partition targeted by the cycle.
+type KeyFunc func(ctx context.Context) string
+
+// pacing spaces out the retries of cycles with the same key.
+type pacing struct {
+	pacer *Pacer
+	key   KeyFunc
+}
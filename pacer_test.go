@@ -0,0 +1,110 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestPacer_Wait(t *testing.T) {
+	const D = 20 * time.Millisecond
+
+	v := &virtual{now: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	p := retry.NewPacer(D)
+	p.Clock = v
+	ctx := context.Background()
+
+	start := v.now
+	for i := 0; i < 3; i++ {
+		if err := p.Wait(ctx, "a"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if elapsed := v.now.Sub(start); elapsed != 2*D {
+		t.Errorf("elapsed %s, want %s", elapsed, 2*D)
+	}
+
+	start = v.now
+	if err := p.Wait(ctx, "b"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := v.now.Sub(start); elapsed != 0 {
+		t.Errorf("elapsed %s, want 0", elapsed)
+	}
+}
+
+func TestPacer_Wait_Cancel(t *testing.T) {
+	p := retry.NewPacer(1 * time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_ = p.Wait(ctx, "a") // the first slot is free
+	if err := p.Wait(ctx, "a"); err != context.Canceled {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestNewPacer(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	retry.NewPacer(0)
+}
+
+func TestCycler_Pace(t *testing.T) {
+	const D = 1 * time.Hour
+
+	p := retry.NewPacer(D)
+	key := func(ctx context.Context) string { return "partition" }
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var waits []time.Duration
+	newCycler := func() *retry.Cycler {
+		c := retry.NewCycler(backoff.Constant(0))
+		c.Clock = backoff.ClockFunc(func() time.Time { return now })
+		c.Limit(2)
+		c.Pace(p, key)
+		c.Sleeper = retry.SleeperFunc(func(ctx context.Context, d time.Duration) error {
+			waits = append(waits, d)
+			return nil
+		})
+		return c
+	}
+
+	_ = newCycler().Try(func(n int) error { return ErrTest })
+	_ = newCycler().Try(func(n int) error { return ErrTest })
+
+	// each cycle sleeps for its delay, then for its slot
+	if len(waits) != 4 {
+		t.Fatalf("got %d waits, want 4", len(waits))
+	}
+	if waits[1] != 0 {
+		t.Errorf("first retry waited %s, want 0", waits[1])
+	}
+	if waits[3] != D {
+		t.Errorf("second retry waited %s, want %s", waits[3], D)
+	}
+}
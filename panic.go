@@ -0,0 +1,73 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+// A PanicError is returned by a retry cycle whose backoff strategy panicked,
+// if panics are recovered (see [Cycler.RecoverPanics]).
+type PanicError struct {
+	Value interface{} // value passed to panic
+	Stack []byte      // stack trace of the panicking goroutine
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("retry: strategy panicked: %v", e.Value)
+}
+
+// Unwrap returns the value passed to panic if it is an error, and nil
+// otherwise.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// RecoverPanics enables or disables the recovery of panics raised by the
+// backoff strategy. By default, a panicking strategy, such as a faulty custom
+// implementation, crashes the goroutine that runs the retry cycle. If enabled,
+// the panic is recovered instead, and the cycle ends with [Panicked], returning
+// a [PanicError] that holds the panic value. Exit handlers observe the event
+// like any other end of a cycle. Panics raised by attempts or handlers are not
+// affected.
+func (c *Cycler) RecoverPanics(enabled bool) {
+	c.audit.touch()
+	c.rescue = enabled
+}
+
+// delay computes the delay after the n-th attempt using strategy, along with
+// the cause if it is backoff.Exit, recovering from panics if enabled.
+func (c *Cycler) delay(
+	strategy backoff.Strategy,
+	n int,
+	start time.Time,
+) (d time.Duration, cause backoff.Cause, err error) {
+	if c.rescue {
+		defer func() {
+			if r := recover(); r != nil {
+				err = &PanicError{Value: r, Stack: debug.Stack()}
+			}
+		}()
+	}
+	d, cause = backoff.Explain(strategy, n, start)
+	return d, cause, nil
+}
@@ -0,0 +1,63 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+)
+
+type panicky struct{}
+
+func (panicky) Delay(n int, start time.Time) time.Duration {
+	panic(ErrTest)
+}
+
+func TestCycler_RecoverPanics(t *testing.T) {
+	cycler := retry.NewCycler(panicky{})
+	cycler.RecoverPanics(true)
+
+	var reason retry.StopReason
+	cycler.OnExit(func(r retry.StopReason, n int, err error) { reason = r })
+
+	err := cycler.Try(func(n int) error { return ErrTest })
+
+	var e *retry.PanicError
+	if !errors.As(err, &e) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !errors.Is(err, ErrTest) || len(e.Stack) == 0 {
+		t.Errorf("unexpected panic error: %#v", e)
+	}
+	if reason != retry.Panicked {
+		t.Errorf("reason was %s, want %s", reason, retry.Panicked)
+	}
+}
+
+func TestCycler_RecoverPanics_Disabled(t *testing.T) {
+	cycler := retry.NewCycler(panicky{})
+
+	defer func() {
+		if recover() != ErrTest {
+			t.Error("expected panic")
+		}
+	}()
+	_ = cycler.Try(func(n int) error { return ErrTest })
+}
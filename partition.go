@@ -0,0 +1,201 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// A PartitionConsumer is the part of a partitioned log consumer, such as a
+// Kafka client, that [Partitions] needs to control the flow of messages.
+type PartitionConsumer interface {
+	// Pause stops fetching messages from the partition.
+	Pause(partition int32)
+	// Resume continues fetching messages from the partition.
+	Resume(partition int32)
+	// Seek rewinds the partition, such that the message at offset is
+	// fetched next.
+	Seek(partition int32, offset int64)
+	// Commit marks all messages before offset as consumed.
+	Commit(ctx context.Context, partition int32, offset int64) error
+}
+
+// A DeadLetterFunc moves a message that can no longer be retried out of the
+// way, e.g., by publishing it to a dead-letter topic. It receives the error of
+// the last attempt.
+type DeadLetterFunc[T any] func(ctx context.Context, msg T, err error) error
+
+// Partitions retries messages of a partitioned log without blocking the
+// consumer. When a message fails, its partition is rewound to the message and
+// paused for the backoff delay, while the other partitions keep flowing. Once
+// the delay has passed, [Partitions.Wake] resumes the partition, and the
+// message is fetched again. The offset of a partition only advances after the
+// message was handled successfully or dead-lettered, so no message is lost if
+// the consumer crashes in between. It is safe for concurrent use. Use
+// [NewPartitions] to create a new instance.
+type Partitions[T any] struct {
+	mu       sync.Mutex
+	cycler   *Cycler
+	consumer PartitionConsumer
+	handler  Handler[T]
+	dead     DeadLetterFunc[T]
+	pending  map[int32]*redelivery // messages awaiting a retry per partition
+}
+
+// redelivery tracks the retries of a message.
+type redelivery struct {
+	offset int64     // offset of the message
+	count  int       // number of deliveries so far
+	first  time.Time // time of the first delivery
+	at     time.Time // time at which the partition resumes
+	paused bool      // whether the partition is paused
+}
+
+// NewPartitions creates a new [Partitions] that handles messages fetched by
+// consumer with handler, applying the retry policy of c (see [Consume]).
+func NewPartitions[T any](
+	c *Cycler,
+	consumer PartitionConsumer,
+	handler Handler[T],
+) *Partitions[T] {
+	return &Partitions[T]{
+		cycler:   c,
+		consumer: consumer,
+		handler:  handler,
+		pending:  make(map[int32]*redelivery),
+	}
+}
+
+// OnDeadLetter registers a function that receives messages which are no
+// longer retried. If it fails, the offset is not committed, and the message is
+// fetched again. Without such function, dead-lettered messages are skipped.
+func (p *Partitions[T]) OnDeadLetter(fn DeadLetterFunc[T]) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.dead = fn
+}
+
+// Handle handles msg, which was fetched from the given partition and offset,
+// and settles it as follows:
+//
+//   - If the handler succeeds, the offset is committed.
+//   - If the message is dead-lettered, it is passed to the function registered
+//     with [Partitions.OnDeadLetter], and the offset is committed.
+//   - If the message is requeued, the partition is rewound to offset, and
+//     paused for the backoff delay.
+//
+// Messages from a partition that is paused are ignored, as they were fetched
+// before the pause took effect, and will be fetched again. The returned
+// [Decision] tells how the message was settled; the error is that of the
+// commit or the dead-letter function, if any.
+func (p *Partitions[T]) Handle(
+	ctx context.Context,
+	partition int32,
+	offset int64,
+	msg T,
+) (Decision, error) {
+	now := p.cycler.Clock.Time()
+	p.mu.Lock()
+	r := p.pending[partition]
+	if r != nil && r.paused {
+		p.mu.Unlock()
+		d := Decision{Verdict: Requeue, At: r.at}
+		if r.at.After(now) {
+			d.Delay = r.at.Sub(now)
+		}
+		return d, nil
+	}
+	if r == nil || r.offset != offset {
+		r = &redelivery{offset: offset, first: now}
+	}
+	r.count++
+	p.mu.Unlock()
+
+	d := Consume(ctx, p.cycler, msg, r.count, r.first, p.handler)
+	switch d.Verdict {
+	case Requeue:
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		r.at = d.At
+		r.paused = d.Delay > 0
+		p.pending[partition] = r
+		p.consumer.Seek(partition, offset)
+		if r.paused {
+			p.consumer.Pause(partition)
+		}
+		return d, nil
+	case DeadLetter:
+		p.mu.Lock()
+		dead := p.dead
+		p.mu.Unlock()
+		if dead != nil {
+			if err := dead(ctx, msg, d.Err); err != nil {
+				p.mu.Lock()
+				p.pending[partition] = r
+				p.mu.Unlock()
+				p.consumer.Seek(partition, offset)
+				return d, err
+			}
+		}
+	}
+	p.mu.Lock()
+	delete(p.pending, partition)
+	p.mu.Unlock()
+	return d, p.consumer.Commit(ctx, partition, offset+1)
+}
+
+// Wake resumes all partitions whose backoff delay has passed. The consumer
+// should call it regularly, e.g., between polls, bounding the poll timeout by
+// [Partitions.Next].
+func (p *Partitions[T]) Wake() {
+	now := p.cycler.Clock.Time()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for partition, r := range p.pending {
+		if r.paused && !r.at.After(now) {
+			r.paused = false
+			p.consumer.Resume(partition)
+		}
+	}
+}
+
+// Next returns the earliest time at which a paused partition is due to be
+// resumed. It returns false if no partition is paused.
+func (p *Partitions[T]) Next() (time.Time, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var next time.Time
+	for _, r := range p.pending {
+		if r.paused && (next.IsZero() || r.at.Before(next)) {
+			next = r.at
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// Revoke forgets the retry state of the given partitions, and should be called
+// when they are reassigned to another consumer. The new owner starts the
+// retry cycles of pending messages afresh.
+func (p *Partitions[T]) Revoke(partitions ...int32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, partition := range partitions {
+		delete(p.pending, partition)
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+// recorder records the calls made to a retry.PartitionConsumer.
+type recorder struct {
+	calls  []string
+	commit error
+}
+
+func (l *recorder) Pause(partition int32)  { l.record("pause %d", partition) }
+func (l *recorder) Resume(partition int32) { l.record("resume %d", partition) }
+
+func (l *recorder) Seek(partition int32, offset int64) {
+	l.record("seek %d@%d", partition, offset)
+}
+
+func (l *recorder) Commit(ctx context.Context, partition int32, offset int64) error {
+	l.record("commit %d@%d", partition, offset)
+	return l.commit
+}
+
+func (l *recorder) record(format string, args ...any) {
+	l.calls = append(l.calls, fmt.Sprintf(format, args...))
+}
+
+func (l *recorder) expect(t *testing.T, exp ...string) {
+	t.Helper()
+	if !reflect.DeepEqual(l.calls, exp) {
+		t.Errorf("calls were %q, want %q", l.calls, exp)
+	}
+	l.calls = nil
+}
+
+func TestPartitions(t *testing.T) {
+	v := &virtual{now: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Second))
+	cycler.Clock = v
+	cycler.Limit(3)
+
+	l := &recorder{}
+	ctx := context.Background()
+	p := retry.NewPartitions(cycler, l, func(ctx context.Context, msg string) error {
+		if msg == "bad" {
+			return ErrTest
+		}
+		return nil
+	})
+
+	var dead []string
+	p.OnDeadLetter(func(ctx context.Context, msg string, err error) error {
+		if !errors.Is(err, ErrTest) {
+			t.Errorf("unexpected error: %v", err)
+		}
+		dead = append(dead, msg)
+		return nil
+	})
+
+	if d, _ := p.Handle(ctx, 0, 10, "bad"); d.Verdict != retry.Requeue {
+		t.Fatalf("unexpected decision: %+v", d)
+	}
+	l.expect(t, "seek 0@10", "pause 0")
+
+	// messages fetched before the pause took effect are ignored
+	if d, _ := p.Handle(ctx, 0, 11, "good"); d.Verdict != retry.Requeue {
+		t.Errorf("unexpected decision: %+v", d)
+	}
+	// other partitions keep flowing
+	if d, _ := p.Handle(ctx, 1, 5, "good"); d.Verdict != retry.Ack {
+		t.Errorf("unexpected decision: %+v", d)
+	}
+	l.expect(t, "commit 1@6")
+
+	if next, ok := p.Next(); !ok || !next.Equal(v.now.Add(1*time.Second)) {
+		t.Errorf("Next() = %s, %t", next, ok)
+	}
+	p.Wake()
+	l.expect(t)
+
+	v.now = v.now.Add(1 * time.Second)
+	p.Wake()
+	l.expect(t, "resume 0")
+	if _, ok := p.Next(); ok {
+		t.Error("expected no paused partitions")
+	}
+
+	p.Handle(ctx, 0, 10, "bad")
+	l.expect(t, "seek 0@10", "pause 0")
+	v.now = v.now.Add(1 * time.Second)
+	p.Wake()
+	l.expect(t, "resume 0")
+
+	if d, _ := p.Handle(ctx, 0, 10, "bad"); d.Verdict != retry.DeadLetter {
+		t.Errorf("unexpected decision: %+v", d)
+	}
+	l.expect(t, "commit 0@11")
+	if !reflect.DeepEqual(dead, []string{"bad"}) {
+		t.Errorf("dead-lettered %q", dead)
+	}
+}
+
+func TestPartitions_OnDeadLetter_Error(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Second))
+	cycler.RetryIf(func(err error) bool { return false })
+
+	l := &recorder{}
+	p := retry.NewPartitions(cycler, l, func(ctx context.Context, msg int) error {
+		return ErrTest
+	})
+	p.OnDeadLetter(func(ctx context.Context, msg int, err error) error {
+		return ErrFatal
+	})
+
+	if _, err := p.Handle(context.Background(), 2, 7, 0); err != ErrFatal {
+		t.Errorf("unexpected error: %v", err)
+	}
+	l.expect(t, "seek 2@7")
+}
+
+func TestPartitions_Commit_Error(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Second))
+
+	l := &recorder{commit: ErrTest}
+	p := retry.NewPartitions(cycler, l, func(ctx context.Context, msg int) error {
+		return nil
+	})
+
+	if _, err := p.Handle(context.Background(), 0, 0, 0); err != ErrTest {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestPartitions_Revoke(t *testing.T) {
+	v := &virtual{now: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Second))
+	cycler.Clock = v
+
+	l := &recorder{}
+	p := retry.NewPartitions(cycler, l, func(ctx context.Context, msg int) error {
+		return ErrTest
+	})
+
+	p.Handle(context.Background(), 3, 0, 0)
+	p.Revoke(3)
+	if _, ok := p.Next(); ok {
+		t.Error("expected no paused partitions")
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// A ProgressError reports how much of its work a failed attempt completed.
+// Use [Progress] to wrap an error such that the delay before the next retry
+// depends on the progress made. See [Cycler.ProgressFactor].
+type ProgressError struct {
+	Cause error
+	Done  float64 // fraction of the work done, in [0,1]
+}
+
+func (e *ProgressError) Error() string { return e.Cause.Error() }
+
+func (e *ProgressError) Unwrap() error { return e.Cause }
+
+// Progress wraps err in a [ProgressError], reporting that the failed attempt
+// completed the given fraction of its work. Attempts that process data in
+// chunks, such as uploads or batch jobs, can report their progress this way to
+// retry sooner while they are making headway. If err is nil, Progress returns
+// nil. The function panics if done is not in [0,1].
+func Progress(err error, done float64) error {
+	if done < 0 || done > 1 {
+		panic(fmt.Sprintf("done %f not in [0,1]", done))
+	}
+	if err == nil {
+		return nil
+	}
+	return &ProgressError{Cause: err, Done: done}
+}
+
+// A FactorFunc maps the progress of a failed attempt to the factor by which
+// the next delay is scaled. See [Cycler.ProgressFactor].
+type FactorFunc func(done float64) float64
+
+// LinearFactor is the default [FactorFunc]. It doubles the delay after an
+// attempt without progress, keeps it for half of the work done, and retries
+// right away after an attempt that completed all of its work.
+func LinearFactor(done float64) float64 {
+	return 2 * (1 - done)
+}
+
+// ProgressFactor sets the function that scales the delay after an attempt
+// that failed with a [ProgressError], based on the progress it reported. This
+// shortens delays while attempts are making progress, and lengthens them when
+// attempts fail without progress. Delays after other errors remain unchanged.
+// If fn is nil, [LinearFactor] is used.
+func (c *Cycler) ProgressFactor(fn FactorFunc) {
+	c.audit.touch()
+	c.factor = fn
+}
+
+// scale adjusts delay according to the progress reported by err, if any.
+func (c *Cycler) scale(delay time.Duration, err error) time.Duration {
+	var e *ProgressError
+	if !errors.As(err, &e) {
+		return delay
+	}
+	fn := c.factor
+	if fn == nil {
+		fn = LinearFactor
+	}
+	f := float64(delay) * fn(e.Done)
+	switch {
+	case f <= 0:
+		return 0
+	case f >= math.MaxInt64:
+		return math.MaxInt64
+	default:
+		return time.Duration(f)
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestProgress(t *testing.T) {
+	if err := retry.Progress(nil, 0.5); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err := retry.Progress(ErrTest, 0.5)
+	if err.Error() != ErrTest.Error() {
+		t.Errorf("message was %q, want %q", err.Error(), ErrTest.Error())
+	}
+}
+
+func TestProgress_Panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	_ = retry.Progress(ErrTest, 1.5)
+}
+
+func TestCycler_ProgressFactor(t *testing.T) {
+	const D = 1 * time.Second
+
+	for _, test := range []struct {
+		name   string
+		factor retry.FactorFunc
+		exp    []time.Duration
+	}{
+		{"default", nil, []time.Duration{2 * D, D / 2, D}},
+		{"custom", func(done float64) float64 { return 3 }, []time.Duration{3 * D, 3 * D, D}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			cycler := retry.NewCycler(backoff.Constant(D))
+			cycler.ProgressFactor(test.factor)
+			cycler.Limit(4)
+			cycler.Sleeper = retry.SleeperFunc(func(context.Context, time.Duration) error {
+				return nil
+			})
+
+			var ds []time.Duration
+			cycler.OnError(func(n int, delay time.Duration, err error) {
+				ds = append(ds, delay)
+			})
+
+			_ = cycler.Try(func(n int) error {
+				switch n {
+				case 1:
+					return retry.Progress(ErrTest, 0)
+				case 2:
+					return retry.Progress(ErrTest, 0.75)
+				default:
+					return ErrTest
+				}
+			})
+
+			if !reflect.DeepEqual(ds, test.exp) {
+				t.Errorf("delays were %v, want %v", ds, test.exp)
+			}
+		})
+	}
+}
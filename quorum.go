@@ -0,0 +1,99 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNoQuorum is matched by a [QuorumError] through errors.Is.
+var ErrNoQuorum = errors.New("retry: no quorum")
+
+// A QuorumError is returned by [Quorum] if too many replicas failed for the
+// quorum to be reached.
+type QuorumError struct {
+	Errs []error // errors by replica; nil for replicas that did not fail
+	K    int     // number of successes required
+}
+
+func (e *QuorumError) Error() string {
+	failed := 0
+	for _, err := range e.Errs {
+		if err != nil {
+			failed++
+		}
+	}
+	return fmt.Sprintf(
+		"retry: quorum of %d not reached: %d of %d replicas failed",
+		e.K, failed, len(e.Errs),
+	)
+}
+
+// Is reports whether target is [ErrNoQuorum].
+func (e *QuorumError) Is(target error) bool { return target == ErrNoQuorum }
+
+// Quorum runs each of the attempts, typically one per replica, in its own retry
+// cycle scheduled by c, and succeeds as soon as k of the cycles have succeeded.
+// This suits quorum reads and writes, where partial success is the success
+// condition. Once the outcome is decided, the cycles still running are
+// cancelled through their context, and Quorum returns without waiting for
+// attempts that are currently executing. If so many cycles fail that k
+// successes are out of reach, a [QuorumError] holding the errors of the failed
+// cycles is returned. The function panics if k is not in [1,len(attempts)].
+func Quorum(
+	ctx context.Context,
+	c *Cycler,
+	attempts []AttemptFunc,
+	k int,
+) error {
+	if k < 1 || k > len(attempts) {
+		panic(fmt.Sprintf("k = %d, must be in [1,%d]", k, len(attempts)))
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		i   int
+		err error
+	}
+	results := make(chan result, len(attempts))
+	for i, attempt := range attempts {
+		go func(i int, attempt AttemptFunc) {
+			results <- result{i, c.TryWithContext(ctx, attempt)}
+		}(i, attempt)
+	}
+
+	errs := make([]error, len(attempts))
+	succeeded, failed := 0, 0
+	for range attempts {
+		r := <-results
+		if r.err == nil {
+			if succeeded++; succeeded == k {
+				return nil
+			}
+			continue
+		}
+		errs[r.i] = r.err
+		if failed++; failed > len(attempts)-k {
+			return &QuorumError{Errs: errs, K: k}
+		}
+	}
+	// unreachable, since every result decides the outcome eventually
+	return &QuorumError{Errs: errs, K: k}
+}
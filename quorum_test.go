@@ -0,0 +1,89 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestQuorum(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Limit(3)
+
+	ok := func(n int) error { return nil }
+	flaky := func(n int) error {
+		if n < 2 {
+			return ErrTest
+		}
+		return nil
+	}
+	down := func(n int) error { return ErrTest }
+
+	err := retry.Quorum(context.Background(), cycler, []retry.AttemptFunc{ok, flaky, down}, 2)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestQuorum_Unreachable(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Limit(2)
+
+	ok := func(n int) error { return nil }
+	down := func(n int) error { return ErrTest }
+
+	err := retry.Quorum(context.Background(), cycler, []retry.AttemptFunc{ok, down, down}, 2)
+
+	var e *retry.QuorumError
+	if !errors.As(err, &e) || !errors.Is(err, retry.ErrNoQuorum) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Errs[0] != nil || e.Errs[1] == nil || e.Errs[2] == nil {
+		t.Errorf("unexpected errors: %v", e.Errs)
+	}
+}
+
+func TestQuorum_CancelsStragglers(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Hour))
+
+	ok := func(n int) error { return nil }
+	slow := func(n int) error { return ErrTest }
+
+	start := time.Now()
+	err := retry.Quorum(context.Background(), cycler, []retry.AttemptFunc{ok, slow}, 1)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 1*time.Second {
+		t.Errorf("elapsed %s, want quick return", elapsed)
+	}
+}
+
+func TestQuorum_Panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	_ = retry.Quorum(context.Background(), retry.NewCycler(backoff.Once), nil, 1)
+}
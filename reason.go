@@ -0,0 +1,56 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+// A StopReason tells why a retry cycle has ended.
+type StopReason int
+
+const (
+	// Succeeded indicates that an attempt completed successfully.
+	Succeeded StopReason = iota
+	// LimitReached indicates that the maximum number of attempts was reached,
+	// or that the backoff strategy signalled the end of the cycle otherwise.
+	LimitReached
+	// TimedOut indicates that the maximum duration of the cycle was exceeded.
+	TimedOut
+	// ContextCancelled indicates that the context of the cycle was cancelled
+	// or exceeded its deadline.
+	ContextCancelled
+	// ForcedExit indicates that an attempt returned an [ExitError], or an
+	// error not classified as retryable (see [Cycler.RetryIf]), or that a
+	// hook registered with [Cycler.BeforeAttempt] failed.
+	ForcedExit
+	// Panicked indicates that the backoff strategy panicked, and the panic was
+	// recovered (see [Cycler.RecoverPanics]).
+	Panicked
+)
+
+var reasons = [...]string{
+	Succeeded:        "succeeded",
+	LimitReached:     "limit reached",
+	TimedOut:         "timed out",
+	ContextCancelled: "context cancelled",
+	ForcedExit:       "forced exit",
+	Panicked:         "panicked",
+}
+
+func (r StopReason) String() string {
+	if r < 0 || int(r) >= len(reasons) {
+		return "unknown"
+	}
+	return reasons[r]
+}
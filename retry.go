@@ -20,12 +20,13 @@
 // The fundamental structure is called [Cycler]. A cycler can be obtained by
 // passing an appropriate [backoff.Strategy] to [NewCycler]. Any function whose
 // signature matches [AttemptFunc] can then be retried using either [Cycler.Try]
-// or [Cycler.TryWithContext].
+// or [Cycler.TryWithContext]. Context-aware functions matching
+// [ContextAttemptFunc] are retried using [Cycler.Run].
 package retry
 
 import (
 	"context"
-	"math/rand"
+	"errors"
 	"time"
 
 	"github.com/deep-rent/retry/backoff"
@@ -38,19 +39,41 @@
 	// n = 1.
 	AttemptFunc func(n int) error
 
+	// A ContextAttemptFunc is an [AttemptFunc] that additionally receives a
+	// context scoped to the current attempt. See [Cycler.Run] for details.
+	ContextAttemptFunc func(ctx context.Context, n int) error
+
 	// An ErrorHandlerFunc is invoked when the n-th execution of an
 	// [AttemptFunc] failed with err, and the next retry is pending after delay
 	// has passed. Note that the initial execution corresponds to n = 1.
 	ErrorHandlerFunc func(n int, delay time.Duration, err error)
-)
 
-// seed a new pseudo-random number generator
-var rd *rand.Rand = rand.New(rand.NewSource(time.Now().UTC().UnixNano()))
+	// A ContextErrorHandlerFunc is an [ErrorHandlerFunc] that additionally
+	// receives the context of the retry cycle.
+	ContextErrorHandlerFunc func(
+		ctx context.Context,
+		n int,
+		delay time.Duration,
+		err error,
+	)
 
-// random is the default implementation of [backoff.Random].
-func random() float64 {
-	return rd.Float64()
-}
+	// A BeforeAttemptFunc is invoked before the n-th execution of an
+	// [AttemptFunc], and receives the context of the retry cycle. Returning an
+	// error aborts the cycle. See [Cycler.BeforeAttempt].
+	BeforeAttemptFunc func(ctx context.Context, n int) error
+
+	// A FailureHandlerFunc is invoked with the details of a failed attempt.
+	FailureHandlerFunc func(f Failure)
+
+	// A RedactFunc transforms an error before it is shown, e.g. by stripping
+	// sensitive details. See [Cycler.Redact].
+	RedactFunc func(err error) error
+
+	// An ExitHandlerFunc is invoked when a retry cycle has ended after n
+	// attempts. The reason tells why the cycle has ended, and err is the error
+	// returned to the caller, which is nil if the cycle succeeded.
+	ExitHandlerFunc func(reason StopReason, n int, err error)
+)
 
 // An ExitError signals that an [AttemptFunc] should no longer be retried. Use
 // [ForceExit] to wrap an error such that it forces the current retry cycle to
@@ -67,18 +90,86 @@ func ForceExit(err error) error {
 	return &ExitError{Cause: err}
 }
 
+// ErrUnbounded is returned by [Cycler.Validate] if retry cycles are not
+// guaranteed to end.
+var ErrUnbounded = errors.New("retry: unbounded retry cycle")
+
 // now is the default implementation of [backoff.Clock].
 var now backoff.Clock = backoff.ClockFunc(func() time.Time {
 	return time.Now()
 })
 
+// Labels are arbitrary key-value pairs attached to a [Cycler], such that
+// services running multiple cyclers can tell their policies apart in logs,
+// metrics and reports.
+type Labels map[string]string
+
 // A Cycler is used to schedule retry cycles in which an [AttemptFunc] is
 // repeatedly executed until it succeeds. Once configured, the same cycler can
 // be used to schedule any number of retry cycles.
+//
+// The Clock of a cycler determines the reference time of retry cycles. The
+// Sleeper waits for the delays between consecutive attempts. If nil, the cycler
+// falls back to [ClockSleeper], such that a Clock that also implements
+// [backoff.Timer] controls when delays elapse. The Name and Labels identify the
+// policy of the cycler in telemetry.
 type Cycler struct {
-	strategy backoff.Strategy
-	handlers []ErrorHandlerFunc
-	Clock    backoff.Clock // used to track the execution time of retry cycles
+	stats      *counters
+	strategy   backoff.Strategy
+	decorators []decorator
+	handlers   []ContextErrorHandlerFunc
+	befores    []BeforeAttemptFunc
+	exits      []ExitHandlerFunc
+	failures   []FailureHandlerFunc
+	instrs     []Instrument
+	notifiers  []Notifier
+	wait       time.Duration // maximum cumulative waiting time
+	strict     bool          // refuse to run unbounded retry cycles
+	timeout    time.Duration // maximum duration of retry cycles
+	split      bool          // split deadlines among remaining attempts
+	inherit    bool          // derive the timeout from the context deadline
+	min        int           // minimum number of attempts
+	perAttempt time.Duration // maximum duration of a single attempt
+	history    int           // number of failures to remember
+	seed       int64         // fixed seed for all cycles
+	seeded     bool          // whether seed is set
+	throttle   *Throttle     // limits the rate of new cycles
+	block      bool          // whether to wait for the throttle
+	retries    *Throttle     // limits the rate of retries
+	queue      bool          // whether to wait for the retry throttle
+	cost       int           // cost of a retry against the retry throttle
+	pacing     *pacing       // spaces out retries across cycles
+	debounce   *debounce     // enforces a gap between cycles
+	compensate bool          // deduct attempt durations from delays
+	cadence    bool          // schedule attempts at fixed offsets
+	overrun    Overrun       // overrun policy of the fixed cadence
+	classifier Classifier    // decides which errors are retried
+	category   Categorizer   // assigns retried errors to categories
+	taxonomy   *taxonomy     // counts retries by category
+	grace      time.Duration // period in which all errors are retried
+	redact     RedactFunc    // transforms errors before they are shown
+	repeat     *repeat       // ends cycles on repeated failures
+	stop       Condition     // ends cycles on custom conditions
+	factor     FactorFunc    // scales delays by the progress of attempts
+	rescue     bool          // recover panics raised by the strategy
+	chaos      *chaos        // injects faults for testing
+	initial    time.Duration // fixed delay before the first attempt
+	stagger    time.Duration // maximum random delay before the first attempt
+	skip       bool          // retry immediately after the first failure
+	golden     bool          // draw jitter from a low-discrepancy sequence
+	policy     PolicyFunc    // resolves the backoff strategy per cycle
+	target     TargetFunc    // supplies the target of each attempt
+	handoff    SwitchFunc    // switches strategies in the middle of cycles
+	cooldown   *cooldown     // failing state after exhaustion
+	avail      *Availability // skips retries while availability is low
+	audit      *audit        // detects unsafe concurrent use
+	running    *inflight     // cycles that are currently running
+	sample     *sampler      // samples failed attempts for telemetry
+	coalesce   time.Duration // minimum time between handler invocations
+	Clock      backoff.Clock // used to track the execution time of retry cycles
+	Sleeper    Sleeper       // waits between attempts; see [ClockSleeper]
+	Name       string        // name of the policy, used in telemetry
+	Labels     Labels        // arbitrary labels, used in telemetry
 }
 
 // NewCycler creates a new retry [Cycler]. The specified [backoff.Strategy]
@@ -86,22 +177,275 @@ type Cycler struct {
 // to be reused; recreating the same cycler should be avoided.
 func NewCycler(strategy backoff.Strategy) *Cycler {
 	return &Cycler{
+		stats:    &counters{},
+		taxonomy: &taxonomy{},
+		running:  &inflight{},
 		strategy: strategy,
 		Clock:    now,
 	}
 }
 
+// RetryIf sets a [Classifier] to decide which errors are worth retrying. An
+// attempt failing with an error that is not classified as retryable ends the
+// retry cycle right away, just as if the error had been wrapped in an
+// [ExitError]. If classifier is nil, all errors are retried.
+func (c *Cycler) RetryIf(classifier Classifier) {
+	c.audit.touch()
+	c.classifier = classifier
+}
+
+// Grace sets a grace period at the start of each retry cycle, during which all
+// errors are retried regardless of the [Classifier] set via [Cycler.RetryIf].
+// Once the period has elapsed, the classifier applies strictly. This covers
+// startup races, where even errors that look permanent are actually
+// transient, such as "not found" before a newly created resource has
+// propagated. Errors wrapped in an [ExitError] still end the cycle right away.
+// If d <= 0, no grace period will be granted.
+func (c *Cycler) Grace(d time.Duration) {
+	c.audit.touch()
+	c.grace = d
+}
+
+// Redact sets a function to transform errors before they reach handlers,
+// instruments, notifiers, traces and the history of a [CycleError]. This allows
+// for stripping sensitive details, such as credentials in URLs, in one central
+// place instead of in every callback. The function is only called with non-nil
+// errors, which may include errors wrapped in a [CycleError] or an
+// [ExitError]. The errors returned to the caller of a retry cycle remain
+// untouched, except for the history they may carry. If redact is nil, or if it
+// returns nil for some error, that error is shown as is.
+func (c *Cycler) Redact(redact RedactFunc) {
+	c.audit.touch()
+	c.redact = redact
+}
+
+// show returns the error to be shown in place of err.
+func (c *Cycler) show(err error) error {
+	if err == nil || c.redact == nil {
+		return err
+	}
+	if shown := c.redact(err); shown != nil {
+		return shown
+	}
+	return err
+}
+
+// Fingerprint makes retry cycles give up early once m consecutive attempts
+// failed with errors of identical fingerprint, as computed by fn. Identical
+// deterministic failures rarely benefit from further attempts. Errors with an
+// empty fingerprint are never considered identical. Cycles that give up this
+// way end just as if the attempt limit was reached. If fn is nil or m < 1, no
+// such limit will be applied.
+func (c *Cycler) Fingerprint(fn FingerprintFunc, m int) {
+	c.audit.touch()
+	if fn == nil || m < 1 {
+		c.repeat = nil
+		return
+	}
+	c.repeat = &repeat{print: fn, m: m}
+}
+
 // OnError registers a callback to be invoked when a failed [AttemptFunc] needs
 // to be retried. Typically, these callbacks are used to log intermediate errors
 // that would otherwise remain unhandled.
 func (c *Cycler) OnError(handler ErrorHandlerFunc) {
+	c.OnErrorContext(func(
+		_ context.Context,
+		n int,
+		d time.Duration,
+		err error,
+	) {
+		handler(n, d, err)
+	})
+}
+
+// OnErrorContext works like [Cycler.OnError], but the callback additionally
+// receives the context passed to the retry cycle. This allows for context-aware
+// work, such as emitting spans or fetching request-scoped loggers. Handlers may
+// check whether the context is already done to skip expensive work, since the
+// cycle then ends without waiting for the delay.
+func (c *Cycler) OnErrorContext(handler ContextErrorHandlerFunc) {
+	c.audit.touch()
 	c.handlers = append(c.handlers, handler)
 }
 
+// BeforeAttempt registers a hook to be invoked before each attempt, including
+// the initial one. Hooks are intended for preparing the next attempt, such as
+// refreshing expired credentials after an authentication failure, or
+// re-resolving endpoints. If a hook returns an error, the retry cycle ends
+// right away with [ForcedExit], and the error is returned to the caller
+// without executing the attempt.
+func (c *Cycler) BeforeAttempt(hook BeforeAttemptFunc) {
+	c.audit.touch()
+	c.befores = append(c.befores, hook)
+}
+
+// OnFailure registers a callback to be invoked whenever an attempt fails,
+// including the last attempt of a cycle that gives up. Other than the callbacks
+// registered with [Cycler.OnError], these callbacks receive the full details of
+// the failure, such as the time it took to execute the attempt. This helps to
+// distinguish attempts that failed instantly from those that hung for a long
+// time. Attempts that return an [ExitError] are not reported.
+func (c *Cycler) OnFailure(handler FailureHandlerFunc) {
+	c.audit.touch()
+	c.failures = append(c.failures, handler)
+}
+
+// OnExit registers a callback to be invoked when a retry cycle has ended,
+// regardless of whether it succeeded or not. Typically, these callbacks are
+// used for instrumentation purposes, e.g. to break down cycle outcomes by
+// [StopReason].
+func (c *Cycler) OnExit(handler ExitHandlerFunc) {
+	c.audit.touch()
+	c.exits = append(c.exits, handler)
+}
+
+// Notify registers a [Notifier] to be alerted whenever a retry cycle gives up
+// after exceeding some limit. Cycles that succeed, are cancelled, or end due to
+// an [ExitError] are not reported.
+func (c *Cycler) Notify(n Notifier) {
+	c.audit.touch()
+	c.notifiers = append(c.notifiers, n)
+}
+
+// Instrument registers an [Instrument] to observe the attempt durations and
+// backoff delays of retry cycles.
+func (c *Cycler) Instrument(i Instrument) {
+	c.audit.touch()
+	c.instrs = append(c.instrs, i)
+}
+
+// decorate adds d to the decorators applied to the backoff strategy at the
+// start of each retry cycle. Invalid arguments captured by d cause a panic
+// right away.
+func (c *Cycler) decorate(d decorator) {
+	_ = d(backoff.Once, newCycle(0))
+	c.decorators = append(c.decorators, d)
+}
+
+// build assembles the backoff strategy for the retry cycle cy, which runs in
+// the given context.
+func (c *Cycler) build(ctx context.Context, cy *cycle) backoff.Strategy {
+	s := c.strategy
+	if c.policy != nil {
+		if p := c.policy(ctx); p != nil {
+			s = p
+		}
+	}
+	if c.handoff != nil {
+		cy.sw = &switcher{fn: c.handoff, base: s, current: s}
+		s = cy.sw
+	}
+	if c.skip {
+		s = backoff.SkipFirst(s)
+	}
+	for _, d := range c.decorators {
+		s = d(s, cy)
+	}
+	if d := c.inherited(ctx); d > 0 {
+		s = backoff.Timeout(s, d, c.Clock)
+	}
+	if b := budgetFrom(ctx); b != nil && !b.deadline.IsZero() {
+		// nested cycles end with the enclosing cycle
+		if d := b.deadline.Sub(c.Clock.Time()); d > 0 {
+			s = backoff.Timeout(s, d, c.Clock)
+		} else {
+			s = backoff.Once
+		}
+	}
+	if c.min > 1 {
+		s = backoff.MinAttempts(s, c.min)
+	}
+	if n, ok := limitFrom(ctx); ok {
+		s = backoff.Limit(s, n)
+	}
+	return s
+}
+
+// inherited returns the time left until the deadline of ctx if deadline
+// inheritance is enabled, and 0 otherwise. See [Cycler.InheritDeadline].
+func (c *Cycler) inherited(ctx context.Context) time.Duration {
+	if !c.inherit {
+		return 0
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0
+	}
+	return time.Until(deadline)
+}
+
+// deadline returns the time by which a cycle that started at start must end,
+// as determined by [Cycler.Timeout], [Cycler.InheritDeadline] and enclosing
+// cycles. It returns the zero time if there is no such limit.
+func (c *Cycler) deadline(ctx context.Context, start time.Time) time.Time {
+	var t time.Time
+	limit := func(d time.Duration) {
+		if u := start.Add(d); t.IsZero() || u.Before(t) {
+			t = u
+		}
+	}
+	if c.timeout > 0 {
+		limit(c.timeout)
+	}
+	if d := c.inherited(ctx); d > 0 {
+		limit(d)
+	}
+	if b := budgetFrom(ctx); b != nil && !b.deadline.IsZero() {
+		limit(b.deadline.Sub(c.Clock.Time()))
+	}
+	return t
+}
+
+// A PolicyFunc resolves the backoff strategy of a retry cycle from the context
+// in which the cycle runs. It returns nil to fall back to the default strategy.
+type PolicyFunc func(ctx context.Context) backoff.Strategy
+
+// PolicyFor registers fn to be consulted at the start of each retry cycle. The
+// strategy it returns replaces the one passed to [NewCycler] for that cycle;
+// all other options, such as [Cycler.Limit] or [Cycler.Jitter], still apply.
+// This way, multi-tenant services can share a single cycler, while applying
+// different policies per tenant or plan, as derived from the request context.
+// Since [Cycler.Validate] has no context, it only checks the default strategy.
+func (c *Cycler) PolicyFor(fn PolicyFunc) {
+	c.audit.touch()
+	c.policy = fn
+}
+
+// Seed fixes the seed from which the pseudo-random numbers used for jitter are
+// generated. By default, each retry cycle draws a new seed, which is reported
+// as part of a [CycleError]. Passing this seed reproduces the exact delays of
+// that cycle, e.g. to replay a production incident in a test environment.
+func (c *Cycler) Seed(seed int64) {
+	c.audit.touch()
+	c.seed = seed
+	c.seeded = true
+}
+
+// Stats returns a snapshot of the statistics of the retry cycles scheduled by
+// this cycler so far. Services can use them to report retry health, e.g. in
+// their admin endpoints. The counters are maintained at negligible cost.
+func (c *Cycler) Stats() Stats {
+	return c.stats.snapshot()
+}
+
 // Cap sets the maximum delay between consecutive attempts. If max <= 0, no
 // limit will be applied.
 func (c *Cycler) Cap(max time.Duration) {
-	c.strategy = backoff.Cap(c.strategy, max)
+	c.audit.touch()
+	c.decorate(func(s backoff.Strategy, _ *cycle) backoff.Strategy {
+		return backoff.Cap(s, max)
+	})
+}
+
+// TimeOfDay adjusts the delays between consecutive attempts depending on the
+// time of day, as read from the clock of the cycler. See [backoff.TimeOfDay]
+// for how the schedule is interpreted. Invalid windows cause a panic.
+func (c *Cycler) TimeOfDay(schedule ...backoff.Window) {
+	c.audit.touch()
+	c.decorate(func(s backoff.Strategy, _ *cycle) backoff.Strategy {
+		return backoff.TimeOfDay(s, c.Clock, schedule...)
+	})
 }
 
 // Jitter randomly spreads delays between consecutive attempts around in time.
@@ -110,21 +454,350 @@ func (c *Cycler) Cap(max time.Duration) {
 // spread of 0.5 results in delays ranging between 50% above and 50% below the
 // values produced by the underlying backoff strategy. If spread = 0, no jitter
 // will be applied.
+//
+// Jitter applies to the delays configured so far. Hence, calling Jitter after
+// [Cycler.Cap] allows delays to exceed the cap. Use [Cycler.CappedJitter] to
+// avoid this.
 func (c *Cycler) Jitter(spread float64) {
-	c.strategy = backoff.Jitter(c.strategy, spread, random)
+	c.audit.touch()
+	c.decorate(func(s backoff.Strategy, cy *cycle) backoff.Strategy {
+		return backoff.Jitter(s, spread, cy.random)
+	})
+}
+
+// ScaledJitter works like [Cycler.Jitter], but the spread factor grows linearly
+// with the attempt count, reaching its maximum at the k-th attempt. See
+// [backoff.ScaledJitter] for details.
+func (c *Cycler) ScaledJitter(spread float64, k int) {
+	c.audit.touch()
+	c.decorate(func(s backoff.Strategy, cy *cycle) backoff.Strategy {
+		return backoff.ScaledJitter(s, spread, k, cy.random)
+	})
+}
+
+// LowDiscrepancy enables or disables low-discrepancy jitter. If enabled, the
+// random numbers used for jitter follow the [backoff.Golden] sequence with a
+// random offset per retry cycle, instead of being uniformly distributed. This
+// spreads successive retries of the same cycle more evenly over the jitter
+// range and reduces accidental clustering. The offset is drawn from the seed
+// of the cycle, which keeps delays reproducible (see [Cycler.Seed]).
+func (c *Cycler) LowDiscrepancy(enabled bool) {
+	c.audit.touch()
+	c.golden = enabled
+}
+
+// JitterAfter works like [Cycler.Jitter], but only applies jitter from the
+// k-th retry onward. See [backoff.JitterAfter] for details.
+func (c *Cycler) JitterAfter(k int, spread float64) {
+	c.audit.touch()
+	c.decorate(func(s backoff.Strategy, cy *cycle) backoff.Strategy {
+		return backoff.JitterAfter(s, k, spread, cy.random)
+	})
+}
+
+// CappedJitter works like [Cycler.Jitter], but additionally caps the jittered
+// delays at max, such that they never exceed the maximum. If max <= 0, no limit
+// will be applied.
+func (c *Cycler) CappedJitter(spread float64, max time.Duration) {
+	c.audit.touch()
+	c.decorate(func(s backoff.Strategy, cy *cycle) backoff.Strategy {
+		return backoff.CappedJitter(s, spread, max, cy.random)
+	})
 }
 
 // Limit sets the maximum number of attempts in a retry cycle. A retry cycle
-// will stop after the n-th attempt. If n < 1, no limit will be applied.
+// will stop after the n-th attempt. The count includes the initial attempt; use
+// [Cycler.LimitRetries] to bound the number of retries instead. If n < 1, no
+// limit will be applied.
 func (c *Cycler) Limit(n int) {
-	c.strategy = backoff.Limit(c.strategy, n)
+	c.audit.touch()
+	c.decorate(func(s backoff.Strategy, _ *cycle) backoff.Strategy {
+		return backoff.Limit(s, n)
+	})
+}
+
+// LimitRetries sets the maximum number of retries in a retry cycle. A retry
+// cycle will stop after the initial attempt plus n retries. If n < 0, no limit
+// will be applied.
+func (c *Cycler) LimitRetries(n int) {
+	c.audit.touch()
+	c.decorate(func(s backoff.Strategy, _ *cycle) backoff.Strategy {
+		return backoff.LimitRetries(s, n)
+	})
 }
 
 // Timeout sets the maximum duration of retry cycles. A retry cycle will stop
-// after the time elapsed since it was scheduled goes past the maximum. If
-// limit <= 0, no timeout will be applied.
+// after the time elapsed since it was scheduled goes past the maximum. It also
+// stops right away, rather than waiting in vain, if the next delay would end
+// after the maximum. If limit <= 0, no timeout will be applied.
 func (c *Cycler) Timeout(limit time.Duration) {
-	c.strategy = backoff.Timeout(c.strategy, limit, c.Clock)
+	c.audit.touch()
+	c.decorate(func(s backoff.Strategy, _ *cycle) backoff.Strategy {
+		return backoff.Timeout(s, limit, c.Clock)
+	})
+	if limit > 0 && (c.timeout <= 0 || limit < c.timeout) {
+		c.timeout = limit
+	}
+}
+
+// StopIf ends retry cycles as soon as cond returns true, which is checked after
+// each failed attempt. This allows feature flags, circuit breakers or shutdown
+// controllers outside of the context tree to halt cycles, which then end with
+// [LimitReached]. See [backoff.StopIf] for details. If cond is nil, no such
+// condition will be applied.
+func (c *Cycler) StopIf(cond func() bool) {
+	c.audit.touch()
+	c.decorate(func(s backoff.Strategy, _ *cycle) backoff.Strategy {
+		return backoff.StopIf(s, cond)
+	})
+}
+
+// StopOn works like [Cycler.StopIf], but ends retry cycles once signal is
+// closed or receives a value. See [backoff.StopOn] for details. If signal is
+// nil, no such condition will be applied.
+func (c *Cycler) StopOn(signal <-chan struct{}) {
+	c.audit.touch()
+	c.decorate(func(s backoff.Strategy, _ *cycle) backoff.Strategy {
+		return backoff.StopOn(s, signal)
+	})
+}
+
+// InheritDeadline enables or disables deadline inheritance. If enabled, the
+// time left until the deadline of the context at the start of a retry cycle
+// acts as an additional timeout (see [Cycler.Timeout]), whichever is shorter.
+// This way, per-request deadlines automatically bound the cycles of a shared
+// cycler, which then give up with [ErrTimeout] rather than being cancelled in
+// the middle of a delay. Contexts without a deadline are not affected.
+func (c *Cycler) InheritDeadline(enabled bool) {
+	c.audit.touch()
+	c.inherit = enabled
+}
+
+// MinAttempts guarantees at least k attempts per retry cycle, including the
+// initial attempt, even if [Cycler.Timeout] or any other limit of the backoff
+// strategy elapses first. Attempts beyond such a limit follow without delay.
+// This suits operations whose policy is to never give up with fewer than k
+// tries, regardless of how slow the first attempt was. Under [Cycler.Run],
+// the contexts of the guaranteed attempts are not bounded by the timeout of
+// the cycle, though [Cycler.AttemptTimeout] still applies. Cancelling the
+// context of a cycle still ends it right away. If k <= 1, no minimum will be
+// applied.
+func (c *Cycler) MinAttempts(k int) {
+	c.audit.touch()
+	c.min = k
+}
+
+// AttemptTimeout sets the maximum duration of a single attempt. The limit is
+// enforced through the context passed to attempts scheduled with [Cycler.Run];
+// an attempt exceeding it is cancelled and then retried as usual, such that a
+// hanging attempt does not block the whole cycle. The limit applies to each
+// attempt on its own, independently of [Cycler.Timeout], although no attempt
+// runs past the end of the cycle. Attempts scheduled with [Cycler.Try] or
+// [Cycler.TryWithContext] receive no context, and hence are not bounded. If
+// limit <= 0, no timeout will be applied.
+func (c *Cycler) AttemptTimeout(limit time.Duration) {
+	c.audit.touch()
+	c.perAttempt = limit
+}
+
+// SplitDeadline enables or disables deadline splitting. If enabled, the time
+// left in a retry cycle is divided evenly among the remaining attempts allowed
+// by the backoff strategy (see [backoff.MaxAttempts]) to derive the deadline of
+// each attempt scheduled with [Cycler.Run]. The time left is determined by the
+// deadline of the context and by [Cycler.Timeout]. This prevents a single slow
+// attempt from consuming the entire budget, leaving no time for retries.
+// Deadlines are not split if no attempt limit is set.
+func (c *Cycler) SplitDeadline(enabled bool) {
+	c.audit.touch()
+	c.split = enabled
+}
+
+// WaitTimeout sets the maximum cumulative time spent waiting between
+// consecutive attempts. Unlike [Cycler.Timeout], the time spent executing the
+// attempts themselves is not taken into account. A retry cycle stops instead of
+// waiting if the next delay would take the sum of all delays past the maximum.
+// This is useful for workloads with long-running attempts. If limit <= 0, no
+// timeout will be applied.
+func (c *Cycler) WaitTimeout(limit time.Duration) {
+	c.audit.touch()
+	c.wait = limit
+}
+
+// Validate checks whether the configuration of the cycler guarantees that retry
+// cycles eventually end, even if the attempt never succeeds. It returns
+// [ErrUnbounded] if neither [Cycler.Limit], [Cycler.Timeout] nor
+// [Cycler.WaitTimeout] is set, and the backoff strategy is not bounded by
+// itself (see [backoff.Bounded]). Calling Validate at startup helps to catch
+// infinite retry loops early.
+func (c *Cycler) Validate() error {
+	if c.wait > 0 {
+		return nil
+	}
+	if backoff.Bounded(c.build(context.Background(), newCycle(0))) {
+		return nil
+	}
+	return ErrUnbounded
+}
+
+// Strict enables or disables strict mode. In strict mode, the cycler refuses to
+// schedule retry cycles if [Cycler.Validate] returns an error. This error is
+// then returned without executing the attempt.
+func (c *Cycler) Strict(enabled bool) {
+	c.audit.touch()
+	c.strict = enabled
+}
+
+// History sets the number of failures to remember within a retry cycle. If a
+// cycle gives up because some limit is exceeded, the last k failures are
+// returned as part of a [CycleError], which wraps the last error. This is
+// useful for diagnostics, e.g. to see that the first failures had a different
+// cause than the later ones. Memory consumption is bounded by k. If k <= 0, no
+// history will be kept.
+func (c *Cycler) History(k int) {
+	c.audit.touch()
+	c.history = k
+}
+
+// Throttle limits the number of retry cycles the cycler may start per time
+// window, protecting dependencies from hot code paths that start excessive
+// numbers of cycles. Beyond that limit, new cycles fail fast with
+// [ErrThrottled] if block is false. Otherwise, they wait until the next window
+// starts, or until the context of the cycle is cancelled. If n < 1 or
+// window <= 0, no limit will be applied.
+func (c *Cycler) Throttle(n int, window time.Duration, block bool) {
+	c.audit.touch()
+	if n < 1 || window <= 0 {
+		c.throttle = nil
+		return
+	}
+	c.throttle = NewThrottle(n, window)
+	c.block = block
+}
+
+// ThrottleRetries limits the rate of retries using t. Unlike [Cycler.Throttle],
+// which limits the number of new cycles, t counts every retry after a failed
+// attempt. Sharing the same throttle among many cyclers caps the total number
+// of retries the group performs per time window, which acts as a safety valve
+// during widespread outages. Beyond that limit, retry cycles give up just as if
+// the attempt limit was reached if block is false. Otherwise, retries queue up
+// after their delay until the next window starts, or until the context of the
+// cycle is cancelled. If t is nil, no limit will be applied.
+func (c *Cycler) ThrottleRetries(t *Throttle, block bool) {
+	c.audit.touch()
+	c.retries = t
+	c.queue = block
+}
+
+// RetryCost sets the cost each retry consumes from the throttle set via
+// [Cycler.ThrottleRetries] (see [Throttle.AllowN]). Sharing one throttle among
+// cyclers with different costs lets a single retry budget arbitrate fairly
+// between cheap and expensive operations, e.g. by charging bulk transfers more
+// than metadata requests. If cost < 1, each retry costs 1.
+func (c *Cycler) RetryCost(cost int) {
+	c.audit.touch()
+	c.cost = cost
+}
+
+// Pace spaces out retries across all cycles that target the same key, as
+// derived from the context of each cycle by key. After its backoff delay, a
+// retry additionally waits for the next free slot of p for that key, such that
+// retries of independent cycles are at least the gap of p apart. Sharing the
+// same pacer among many cyclers smooths the aggregate retry load on a
+// downstream partition, even when many cycles are active at once. The first
+// attempt of a cycle is not paced. If p is nil, no pacing will be applied.
+func (c *Cycler) Pace(p *Pacer, key KeyFunc) {
+	c.audit.touch()
+	if p == nil {
+		c.pacing = nil
+		return
+	}
+	c.pacing = &pacing{pacer: p, key: key}
+}
+
+// Debounce enforces a minimum gap between consecutive retry cycles: after a
+// cycle has ended, new cycles are held back until d has passed. This prevents
+// tight outer loops from defeating the backoff, e.g. by starting over right
+// after a cycle gave up. Held back cycles fail fast with [ErrDebounced] if
+// block is false. Otherwise, they wait until the gap has passed, or until the
+// context of the cycle is cancelled. If d <= 0, no gap will be enforced.
+func (c *Cycler) Debounce(d time.Duration, block bool) {
+	c.audit.touch()
+	if d <= 0 {
+		c.debounce = nil
+		return
+	}
+	c.debounce = &debounce{gap: d, block: block}
+}
+
+// Cooldown puts the cycler into a temporary failing state whenever a retry
+// cycle gives up after exceeding some limit. While in this state, new cycles
+// fail fast by returning the error of the cycle that gave up, without executing
+// any attempt. The failing state ends after d has passed. This acts as a
+// lightweight circuit breaker. If d <= 0, no cooldown will be applied.
+func (c *Cycler) Cooldown(d time.Duration) {
+	c.audit.touch()
+	if d <= 0 {
+		c.cooldown = nil
+		return
+	}
+	c.cooldown = &cooldown{d: d}
+}
+
+// Compensate enables or disables delay compensation. If enabled, the time an
+// attempt took is deducted from the subsequent delay, which is floored at 0.
+// This way, attempts start at roughly the cadence intended by the backoff
+// strategy, rather than at the cadence plus the attempt durations, which is
+// important for polling at target intervals.
+func (c *Cycler) Compensate(enabled bool) {
+	c.audit.touch()
+	c.compensate = enabled
+}
+
+// Cadence switches to fixed-cadence scheduling, where attempts start at
+// absolute offsets from the start of the retry cycle, like the ticks of a
+// ticker. The slot of each attempt begins when the slot of the previous attempt
+// began, plus the delay produced by the backoff strategy, regardless of how
+// long the previous attempt took. The overrun policy determines what happens if
+// an attempt runs past the start of the next slot. Fixed-cadence scheduling
+// supersedes [Cycler.Compensate].
+func (c *Cycler) Cadence(overrun Overrun) {
+	c.audit.touch()
+	c.cadence = true
+	c.overrun = overrun
+}
+
+// InitialDelay makes each retry cycle wait for d before the first attempt. This
+// is useful if the operation cannot possibly succeed right away, e.g. because
+// a resource has just been created and is subject to eventual consistency. The
+// delay is added to that of [Cycler.Stagger], and neither counts towards
+// [Cycler.Timeout] or [Cycler.WaitTimeout]. If the context of the cycle is
+// cancelled while waiting, the attempt is never executed. If d <= 0, no delay
+// will be added.
+func (c *Cycler) InitialDelay(d time.Duration) {
+	c.audit.touch()
+	c.initial = d
+}
+
+// Stagger adds a random delay of up to max before the first attempt of each
+// retry cycle. This de-synchronizes many instances that start the same
+// operation at the same time, such as fetching configuration at process boot.
+// The delay is drawn from the same source as jitter (see [Cycler.Seed]), and
+// it does not count towards [Cycler.Timeout] or [Cycler.WaitTimeout]. If the
+// context of the cycle is cancelled while waiting, the attempt is never
+// executed. If max <= 0, no delay will be added.
+func (c *Cycler) Stagger(max time.Duration) {
+	c.audit.touch()
+	c.stagger = max
+}
+
+// SkipFirst enables or disables an instant first retry. If enabled, the first
+// retry happens immediately, and the backoff strategy only applies from the
+// second retry onward, see [backoff.SkipFirst]. Unlike decorators, this option
+// shifts the base strategy only, such that [Cycler.Limit] and the like still
+// count every attempt.
+func (c *Cycler) SkipFirst(enabled bool) {
+	c.audit.touch()
+	c.skip = enabled
 }
 
 // Try calls [TryWithContext] using [context.Background].
@@ -140,72 +813,468 @@ func (c *Cycler) Try(attempt AttemptFunc) error {
 //  3. an [ExitError] occurs.
 //
 // When an invocation of attempt returns nil before the cycle stops, this method
-// also returns nil. Otherwise, this method returns the last error returned by
-// attempt. If ctx contains an error, this error will be returned instead.
+// also returns nil. If some limit is exceeded, this method returns a
+// [CycleError] wrapping the last error returned by attempt. If an [ExitError]
+// occurs, its cause is returned as is. If ctx contains an error, this error
+// will be returned instead. If ctx was passed to an attempt of [Cycler.Run],
+// the cycle is nested in the enclosing cycle just like with [Cycler.Run].
 //
-// In any case, attempt is guaranteed to be executed at least once. Be aware
-// that retry cycles with neither [Cycler.Limit] nor [Cycler.Timeout] set will
-// run forever if attempt keeps failing.
+// Unless in [Cycler.Strict] mode, attempt is guaranteed to be executed at least
+// once. Be aware that retry cycles with neither [Cycler.Limit],
+// [Cycler.Timeout] nor [Cycler.WaitTimeout] set will run forever if attempt
+// keeps failing.
 func (c *Cycler) TryWithContext(
 	ctx context.Context,
 	attempt AttemptFunc,
 ) error {
-	var t *time.Timer
-	defer func() {
-		if t != nil {
-			t.Stop()
+	return c.run(ctx, func(_ context.Context, n int) error {
+		return attempt(n)
+	}, false)
+}
+
+// Run schedules a retry cycle just like [Cycler.TryWithContext], except that
+// each invocation of attempt receives its own context derived from ctx. The
+// deadline of this context is the earliest of
+//
+//  1. the deadline of ctx,
+//  2. the end of the retry cycle as determined by [Cycler.Timeout], and
+//  3. the deadline of the attempt as determined by [Cycler.AttemptTimeout].
+//
+// The derived context is cancelled as soon as attempt returns. An attempt that
+// fails because its own deadline is exceeded is retried as usual. The context
+// also carries [Metadata] about the attempt, see [MetadataFrom].
+//
+// Retry cycles scheduled with this context, e.g. to retry a sub-step of the
+// attempt, are nested in the cycle. A nested cycle ends no later than the
+// enclosing cycle, and each of its retries consumes one of the retries left to
+// the enclosing cycle as determined by [Cycler.Limit]. Nested cycles give up
+// once that budget is exhausted, which prevents nested retries from
+// multiplying the total latency.
+func (c *Cycler) Run(ctx context.Context, attempt ContextAttemptFunc) error {
+	return c.run(ctx, attempt, true)
+}
+
+// derive derives the context for the n-th attempt within a retry cycle that
+// started at the given time, and allows for at most limit attempts if
+// limit > 0.
+func (c *Cycler) derive(
+	ctx context.Context,
+	start time.Time,
+	n int,
+	limit int,
+) (context.Context, context.CancelFunc) {
+	split := c.split && limit > 0
+
+	var budget time.Duration // time left in the cycle
+	bounded := false         // whether budget is set
+	if c.timeout > 0 && n > c.min {
+		// attempts guaranteed by MinAttempts are not cut short
+		budget = c.timeout - c.Clock.Time().Sub(start)
+		bounded = true
+	}
+	if deadline, ok := ctx.Deadline(); ok && split {
+		if d := time.Until(deadline); !bounded || d < budget {
+			budget = d
+			bounded = true
+		}
+	}
+
+	timeout := c.perAttempt
+	if bounded {
+		if split {
+			// share the budget with the remaining attempts
+			budget /= time.Duration(limit - n + 1)
+		}
+		if timeout <= 0 || budget < timeout {
+			timeout = budget
+		}
+	} else if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// run implements the retry loop. If derive is set, a new context is derived
+// for each attempt.
+func (c *Cycler) run(
+	ctx context.Context,
+	attempt ContextAttemptFunc,
+	derive bool,
+) error {
+	c.audit.enter()
+	defer c.audit.leave()
+
+	if c.strict {
+		if err := c.Validate(); err != nil {
+			return err
+		}
+	}
+
+	sleeper := c.Sleeper
+	if sleeper == nil {
+		sleeper = ClockSleeper(c.Clock)
+	}
+
+	if c.cooldown != nil {
+		if err := c.cooldown.check(c.Clock.Time()); err != nil {
+			return err
+		}
+	}
+
+	if c.debounce != nil {
+		if err := c.debounce.admit(ctx, c.Clock.Time(), sleeper); err != nil {
+			return err
+		}
+	}
+
+	if c.throttle != nil {
+		if !c.block {
+			if !c.throttle.allow(c.Clock.Time(), 1) {
+				return ErrThrottled
+			}
+		} else if err := c.throttle.wait(ctx, 1, c.Clock, sleeper); err != nil {
+			return err
+		}
+	}
+
+	c.stats.start()
+
+	sd := c.seed
+	if !c.seeded {
+		sd = seed()
+	}
+	cy := newCycle(sd)
+	if c.golden {
+		cy.seq = backoff.Golden(cy.random())
+	}
+	strategy := c.build(ctx, cy)
+	if c.chaos != nil {
+		strategy = &inflate{strategy: strategy, chaos: c.chaos}
+		attempt = c.chaos.wrap(attempt)
+	}
+	limit, _ := backoff.MaxAttempts(strategy)
+	id := nextID()
+	ctx = c.running.add(ctx, id, c.Clock.Time())
+	defer c.running.remove(id)
+
+	if c.initial > 0 || c.stagger > 0 {
+		d := c.initial
+		if c.stagger > 0 {
+			d += time.Duration(cy.random() * float64(c.stagger))
+		}
+		c.running.schedule(id, 0, c.Clock.Time().Add(d))
+		if err := sleeper.Sleep(ctx, d); err != nil {
+			return c.exit(ContextCancelled, 0, err)
 		}
-	}()
+	}
+
+	n := 0                   // number of attempts
+	start := c.Clock.Time()  // current time
+	var waited time.Duration // cumulative waiting time
+	slot := start            // start of the current slot
+	deadline := c.deadline(ctx, start)
 
-	n := 0                  // number of attempts
-	start := c.Clock.Time() // current time
+	var pool *budget // budget shared with nested cycles
+	if parent := budgetFrom(ctx); derive || parent != nil {
+		pool = newBudget(parent, start, limit, c.timeout)
+	}
+
+	var rs repeats // repeated fingerprints
+	if c.repeat != nil {
+		rs.repeat = c.repeat
+	}
+
+	var stop StopFunc // stop conditions
+	if c.stop != nil {
+		stop = c.stop()
+	}
+
+	co := coalescer{interval: c.coalesce} // coalesces handler invocations
+
+	var history *ring // most recent failures
+	if c.history > 0 {
+		history = newRing(c.history)
+	}
+
+	rated := c.sample.instruments(c.instrs) // observe sampled failures
+
+	trace := traceFrom(ctx)
+	if trace != nil {
+		*trace = Trace{Start: start, Seed: cy.seed}
+	}
+
+	// end records the end of the cycle in the trace and exits
+	end := func(reason StopReason, err error) error {
+		if trace != nil {
+			trace.End = c.Clock.Time()
+			trace.Reason = reason
+		}
+		return c.exit(reason, n, err)
+	}
 
 	// retry loop
 	for {
+		// prepare the next attempt
+		for _, h := range c.befores {
+			if err := h(ctx, n+1); err != nil {
+				return end(ForcedExit, err)
+			}
+		}
+
 		// increase attempt count
 		n++
+		c.running.schedule(id, n, time.Time{})
 
-		err := attempt(n)
+		var err error
+		t0 := c.Clock.Time()
+		if derive {
+			actx, cancel := c.derive(ctx, start, n, limit)
+			actx = context.WithValue(actx, metadataKey{}, Metadata{
+				Cycle:   id,
+				Attempt: n,
+				Policy:  c.Name,
+				Target:  c.Target(ctx, n),
+			})
+			actx = context.WithValue(actx, budgetKey{}, pool)
+			if trace != nil {
+				// keep nested cycles from overwriting the trace
+				actx = context.WithValue(actx, traceKey{}, (*Trace)(nil))
+			}
+			err = attempt(actx, n)
+			cancel()
+		} else {
+			err = attempt(ctx, n)
+		}
+		t1 := c.Clock.Time()
+		took := t1.Sub(t0)   // duration of the attempt
+		shown := c.show(err) // redacted error
+		if trace != nil {
+			trace.Spans = append(trace.Spans, Span{
+				Attempt: n,
+				Start:   t0,
+				End:     t1,
+				Err:     shown,
+			})
+		}
+		if c.avail != nil {
+			c.avail.record(t1, err == nil)
+		}
+		sampled := err == nil || c.sample.pick()
+		switch {
+		case err == nil:
+			c.observe(c.instrs, n, took, shown)
+		case sampled:
+			c.observe(rated, n, took, shown)
+		}
 		if err == nil {
 			// success
-			return nil
+			return end(Succeeded, nil)
 		}
 
 		// unrecoverable error
 		if e, ok := err.(*ExitError); ok {
-			return e.Cause
+			if !sampled {
+				c.observe(c.instrs, n, took, shown)
+			}
+			return end(ForcedExit, e.Cause)
+		}
+		if c.classifier != nil &&
+			c.Clock.Time().Sub(start) >= c.grace &&
+			!c.classifier(err) {
+			if !sampled {
+				c.observe(c.instrs, n, took, shown)
+			}
+			return end(ForcedExit, err)
 		}
 
-		delay := c.strategy.Delay(n, start)
+		if cy.sw != nil {
+			cy.sw.observe(err)
+		}
+		// cause tells why the strategy ended the cycle, if it did
+		delay, cause, perr := c.delay(strategy, n, start)
+		if perr != nil {
+			return end(Panicked, perr)
+		}
+		if delay != backoff.Exit {
+			delay = c.scale(delay, err)
+		}
+		if c.wait > 0 && delay != backoff.Exit && waited+delay > c.wait {
+			// the next delay would exceed the cumulative waiting time
+			delay, cause = backoff.Exit, backoff.CauseTimeout
+		}
+		if c.repeat != nil && delay != backoff.Exit && rs.observe(err) {
+			delay = backoff.Exit
+		}
+		if pool != nil && delay != backoff.Exit && !pool.take() {
+			delay = backoff.Exit
+		}
+		if c.avail != nil && delay != backoff.Exit &&
+			!c.avail.available(c.Clock.Time()) {
+			delay = backoff.Exit
+		}
+		if stop != nil && delay != backoff.Exit && stop(State{
+			Attempt: n,
+			Elapsed: c.Clock.Time().Sub(start),
+			Waited:  waited,
+			Err:     err,
+		}) {
+			delay = backoff.Exit
+		}
+		if c.retries != nil && !c.queue && delay != backoff.Exit &&
+			!c.retries.allow(c.Clock.Time(), c.retryCost()) {
+			delay = backoff.Exit
+		}
+		if c.cadence && delay != backoff.Exit {
+			delay = schedule(&slot, delay, c.Clock.Time(), c.overrun)
+		} else if c.compensate && delay != backoff.Exit {
+			// deduct the time the attempt took
+			if delay -= took; delay < 0 {
+				delay = 0
+			}
+		}
+		if delay != backoff.Exit && n >= c.min && !deadline.IsZero() &&
+			c.Clock.Time().Add(delay).After(deadline) {
+			// the time limit would pass while waiting
+			delay, cause = backoff.Exit, backoff.CauseTimeout
+		}
+
+		f := Failure{Attempt: n, Time: t0, Duration: took, Err: shown}
+		if delay != backoff.Exit {
+			f.Delay = delay
+		}
+		if history != nil {
+			history.push(f)
+		}
+		if !sampled && delay == backoff.Exit {
+			// always report the final attempt
+			c.observe(c.instrs, n, took, shown)
+			sampled = true
+		}
+		notify, passed := sampled, shown // whether and what to pass to handlers
+		if notify && c.coalesce > 0 {
+			final := delay == backoff.Exit
+			passed, notify = co.admit(shown, c.Clock.Time(), final)
+			f.Err = passed
+		}
+		if notify {
+			for _, h := range c.failures {
+				h(f)
+			}
+		}
 
 		if delay == backoff.Exit {
 			e := ctx.Err()
 			if e != nil {
-				err = e
+				return end(ContextCancelled, e)
+			}
+			reason := LimitReached
+			if cause == backoff.CauseTimeout {
+				reason = TimedOut
+			}
+			if c.notifiers != nil {
+				r := Report{
+					Name:     c.Name,
+					Labels:   c.Labels,
+					Reason:   reason,
+					Attempts: n,
+					Elapsed:  c.Clock.Time().Sub(start),
+					Err:      shown,
+				}
+				for _, x := range c.notifiers {
+					_ = x.Notify(r)
+				}
+			}
+			ce := &CycleError{
+				Cause:  err,
+				Reason: reason,
+				Seed:   cy.seed,
+			}
+			if history != nil {
+				ce.History = history.slice()
 			}
 			// exit early
-			return err
+			return end(reason, ce)
 		}
 
-		// notify error handlers
-		if c.handlers != nil {
+		if trace != nil {
+			trace.Spans[len(trace.Spans)-1].Delay = delay
+		}
+		cat := c.categorize(err)
+		c.taxonomy.add(cat)
+		if sampled {
+			for _, i := range rated {
+				i.ObserveDelay(n, delay)
+				if ci, ok := i.(CategoryInstrument); ok {
+					ci.ObserveCategory(n, cat)
+				}
+			}
+		}
+		if notify {
+			// notify error handlers
 			for _, h := range c.handlers {
-				h(n, delay, err)
+				h(ctx, n, delay, passed)
 			}
 		}
 
-		if t == nil {
-			t = time.NewTimer(delay)
-		} else {
-			t.Reset(delay)
-		}
+		waited += delay
 
-		select {
-		case <-ctx.Done():
+		// wait for delay to elapse
+		c.running.schedule(id, n, c.Clock.Time().Add(delay))
+		if err := sleeper.Sleep(ctx, delay); err != nil {
 			// exit early
-			return ctx.Err()
-		case <-t.C:
-			// wait for delay to elapse
+			return end(ContextCancelled, err)
+		}
+		if c.retries != nil && c.queue {
+			err := c.retries.wait(ctx, c.retryCost(), c.Clock, sleeper)
+			if err != nil {
+				return end(ContextCancelled, err)
+			}
+		}
+		if c.pacing != nil {
+			key := c.pacing.key(ctx)
+			wait := c.pacing.pacer.reserve(c.Clock.Time(), key)
+			if err := sleeper.Sleep(ctx, wait); err != nil {
+				return end(ContextCancelled, err)
+			}
+		}
+	}
+}
+
+// retryCost returns the cost of a retry, see [Cycler.RetryCost].
+func (c *Cycler) retryCost() int {
+	if c.cost < 1 {
+		return 1
+	}
+	return c.cost
+}
+
+// observe passes the outcome of the n-th attempt to instrs.
+func (c *Cycler) observe(
+	instrs []Instrument,
+	n int,
+	took time.Duration,
+	err error,
+) {
+	for _, i := range instrs {
+		i.ObserveAttempt(n, took, err)
+	}
+}
+
+// exit notifies the exit handlers that a retry cycle has ended after n
+// attempts, and passes err through.
+func (c *Cycler) exit(reason StopReason, n int, err error) error {
+	c.stats.end(reason, n)
+	if c.debounce != nil {
+		c.debounce.done(c.Clock.Time())
+	}
+	if c.cooldown != nil && (reason == LimitReached || reason == TimedOut) {
+		c.cooldown.trip(c.Clock.Time(), err)
+	}
+	if c.exits != nil {
+		err := c.show(err)
+		for _, h := range c.exits {
+			h(reason, n, err)
 		}
 	}
+	return err
 }
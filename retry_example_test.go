@@ -147,3 +147,55 @@ func ExampleCycler_TryWithContext() {
 	// attempt #4: failed => wait 10 ms
 	// attempt #5: succeeded
 }
+
+// This example retrieves a value in a retry cycle.
+func ExampleTryValue() {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Limit(3) // stop retrying after 3 attempts
+
+	// start retry cycle
+	v, err := retry.TryValue(cycler, func(n int) (string, error) {
+		if n < 2 {
+			// force retry
+			return "", errors.New("failed")
+		}
+		return fmt.Sprintf("result of attempt #%d", n), nil
+	})
+
+	if err != nil {
+		fmt.Printf("failed after retries: %v", err)
+	} else {
+		fmt.Println(v)
+	}
+
+	// Output:
+	// result of attempt #2
+}
+
+// This example bounds the duration of each attempt, such that a hanging
+// attempt is cancelled and retried rather than blocking the cycle.
+func ExampleCycler_AttemptTimeout() {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.AttemptTimeout(10 * time.Millisecond) // cancel slow attempts
+	cycler.Limit(3)                              // stop retrying after 3 attempts
+
+	// start retry cycle
+	err := cycler.Run(context.Background(), func(ctx context.Context, n int) error {
+		if n == 1 {
+			// hang until the attempt times out
+			<-ctx.Done()
+			fmt.Printf("attempt #%d: %v\n", n, ctx.Err())
+			return ctx.Err()
+		}
+		fmt.Printf("attempt #%d: succeeded\n", n)
+		return nil
+	})
+
+	if err != nil {
+		fmt.Printf("failed after retries: %v", err)
+	}
+
+	// Output:
+	// attempt #1: context deadline exceeded
+	// attempt #2: succeeded
+}
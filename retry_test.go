@@ -18,7 +18,9 @@
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"reflect"
 	"testing"
 	"time"
 
@@ -113,6 +115,26 @@ func TestCycler_OnError(t *testing.T) {
 	}
 }
 
+type key struct{}
+
+func TestCycler_OnErrorContext(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Limit(3)
+
+	var values []interface{}
+	cycler.OnErrorContext(func(ctx context.Context, n int, delay time.Duration, err error) {
+		values = append(values, ctx.Value(key{}))
+	})
+
+	ctx := context.WithValue(context.Background(), key{}, "value")
+	_ = cycler.TryWithContext(ctx, func(n int) error { return ErrTest })
+
+	exp := []interface{}{"value", "value"}
+	if !reflect.DeepEqual(values, exp) {
+		t.Errorf("values were %v, want %v", values, exp)
+	}
+}
+
 func TestCycler_Try_ExitError(t *testing.T) {
 	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
 
@@ -137,3 +159,868 @@ func TestCycler_Try_ExitError(t *testing.T) {
 		t.Errorf("unexpected error: %#v", err)
 	}
 }
+
+func TestCycler_LimitRetries(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.LimitRetries(2)
+
+	const N = 3
+	i := 0
+	err := cycler.Try(func(n int) error {
+		i = n
+		return ErrTest
+	})
+
+	if !errors.Is(err, ErrTest) {
+		t.Errorf("unexpected error: %#v", err)
+	}
+
+	if i != N {
+		t.Errorf("attempts = %d, want %d", i, N)
+	}
+}
+
+func TestCycler_WaitTimeout(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(5 * time.Millisecond))
+	cycler.WaitTimeout(12 * time.Millisecond)
+
+	var slept time.Duration
+	cycler.Sleeper = retry.SleeperFunc(func(_ context.Context, d time.Duration) error {
+		slept += d
+		return nil
+	})
+
+	var reason retry.StopReason
+	cycler.OnExit(func(r retry.StopReason, n int, err error) { reason = r })
+
+	// a third delay would exceed the waiting time
+	const N = 3
+	i := 0
+	err := cycler.Try(func(n int) error {
+		i = n
+		return ErrTest
+	})
+
+	if !errors.Is(err, ErrTest) {
+		t.Errorf("unexpected error: %#v", err)
+	}
+	if i != N {
+		t.Errorf("attempts = %d, want %d", i, N)
+	}
+	if slept != 10*time.Millisecond {
+		t.Errorf("slept %v, want %v", slept, 10*time.Millisecond)
+	}
+	if reason != retry.TimedOut {
+		t.Errorf("reason was %s, want %s", reason, retry.TimedOut)
+	}
+}
+
+func TestCycler_Timeout_BeforeSleep(t *testing.T) {
+	now := time.Now()
+
+	cycler := retry.NewCycler(backoff.Exponential(10*time.Second, 2))
+	cycler.Clock = backoff.ClockFunc(func() time.Time { return now })
+	cycler.Timeout(1 * time.Minute)
+
+	var slept time.Duration
+	cycler.Sleeper = retry.SleeperFunc(func(_ context.Context, d time.Duration) error {
+		slept += d
+		now = now.Add(d)
+		return nil
+	})
+
+	i := 0
+	err := cycler.Try(func(n int) error {
+		i = n
+		return ErrTest
+	})
+
+	if !errors.Is(err, retry.ErrTimeout) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// the third delay of 40s would end after the timeout
+	if i != 3 {
+		t.Errorf("attempts = %d, want 3", i)
+	}
+	if slept != 30*time.Second {
+		t.Errorf("slept %s, want 30s", slept)
+	}
+}
+
+func TestCycler_Validate(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Cap(1 * time.Millisecond)
+
+	if err := cycler.Validate(); err != retry.ErrUnbounded {
+		t.Errorf("unexpected error: %#v", err)
+	}
+
+	cycler.Limit(3)
+
+	if err := cycler.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCycler_Strict(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Strict(true)
+
+	err := cycler.Try(func(n int) error {
+		t.Fatalf("unexpected attempt")
+		return nil
+	})
+
+	if err != retry.ErrUnbounded {
+		t.Errorf("unexpected error: %#v", err)
+	}
+}
+
+func TestCycler_AttemptTimeout(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.AttemptTimeout(5 * time.Millisecond)
+
+	const N = 2
+	err := cycler.Run(context.Background(), func(ctx context.Context, n int) error {
+		if n < N {
+			// hang until the attempt times out
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCycler_Run_Deadline(t *testing.T) {
+	const D = 50 * time.Millisecond
+
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.AttemptTimeout(time.Hour)
+	cycler.Timeout(D)
+
+	err := cycler.Run(context.Background(), func(ctx context.Context, n int) error {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			t.Fatalf("attempt context has no deadline")
+		}
+		if d := time.Until(deadline); d > D {
+			t.Errorf("deadline in %s, want <= %s", d, D)
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCycler_OnExit(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, test := range []struct {
+		exp     retry.StopReason
+		ctx     context.Context
+		setup   func(*retry.Cycler)
+		attempt retry.AttemptFunc
+	}{
+		{
+			exp:     retry.Succeeded,
+			ctx:     context.Background(),
+			setup:   func(c *retry.Cycler) {},
+			attempt: func(n int) error { return nil },
+		},
+		{
+			exp:     retry.LimitReached,
+			ctx:     context.Background(),
+			setup:   func(c *retry.Cycler) { c.Limit(2) },
+			attempt: func(n int) error { return ErrTest },
+		},
+		{
+			exp:     retry.TimedOut,
+			ctx:     context.Background(),
+			setup:   func(c *retry.Cycler) { c.Timeout(5 * time.Millisecond) },
+			attempt: func(n int) error { return ErrTest },
+		},
+		{
+			exp:     retry.ContextCancelled,
+			ctx:     cancelled,
+			setup:   func(c *retry.Cycler) {},
+			attempt: func(n int) error { return ErrTest },
+		},
+		{
+			exp:     retry.ForcedExit,
+			ctx:     context.Background(),
+			setup:   func(c *retry.Cycler) {},
+			attempt: func(n int) error { return retry.ForceExit(ErrTest) },
+		},
+	} {
+		cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+		test.setup(cycler)
+
+		calls := 0
+		cycler.OnExit(func(reason retry.StopReason, n int, err error) {
+			calls++
+			if reason != test.exp {
+				t.Errorf("reason = %s, want %s", reason, test.exp)
+			}
+		})
+
+		_ = cycler.TryWithContext(test.ctx, test.attempt)
+
+		if calls != 1 {
+			t.Errorf("%s: exit handler called %d times, want 1", test.exp, calls)
+		}
+	}
+}
+
+func TestCycler_OnFailure(t *testing.T) {
+	const D = 5 * time.Millisecond
+
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Limit(2)
+
+	var fs []retry.Failure
+	cycler.OnFailure(func(f retry.Failure) {
+		fs = append(fs, f)
+	})
+
+	_ = cycler.Try(func(n int) error {
+		time.Sleep(D)
+		return ErrTest
+	})
+
+	if len(fs) != 2 {
+		t.Fatalf("failures reported: %d, want 2", len(fs))
+	}
+
+	for i, f := range fs {
+		if f.Attempt != i+1 {
+			t.Errorf("failure #%d: attempt = %d", i+1, f.Attempt)
+		}
+		if f.Duration < D {
+			t.Errorf("failure #%d: duration = %s, want >= %s", i+1, f.Duration, D)
+		}
+	}
+}
+
+func TestCycler_Compensate(t *testing.T) {
+	const D = 10 * time.Millisecond
+
+	cycler := retry.NewCycler(backoff.Constant(D))
+	cycler.Limit(2)
+	cycler.Compensate(true)
+
+	var delay time.Duration
+	cycler.OnError(func(n int, d time.Duration, err error) {
+		delay = d
+	})
+
+	_ = cycler.Try(func(n int) error {
+		time.Sleep(4 * time.Millisecond)
+		return ErrTest
+	})
+
+	if delay > D-4*time.Millisecond {
+		t.Errorf("delay = %s, want <= %s", delay, D-4*time.Millisecond)
+	}
+}
+
+func TestCycler_Compensate_Floor(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Limit(2)
+	cycler.Compensate(true)
+
+	delay := time.Duration(-1)
+	cycler.OnError(func(n int, d time.Duration, err error) {
+		delay = d
+	})
+
+	_ = cycler.Try(func(n int) error {
+		time.Sleep(2 * time.Millisecond)
+		return ErrTest
+	})
+
+	if delay != 0 {
+		t.Errorf("delay = %s, want 0", delay)
+	}
+}
+
+func TestCycler_SplitDeadline(t *testing.T) {
+	const D = 400 * time.Millisecond
+
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Limit(4)
+	cycler.SplitDeadline(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), D)
+	defer cancel()
+
+	err := cycler.Run(ctx, func(ctx context.Context, n int) error {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			t.Fatalf("attempt context has no deadline")
+		}
+		if d := time.Until(deadline); d > D/4 {
+			t.Errorf("deadline in %s, want <= %s", d, D/4)
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCycler_SplitDeadline_Strategy(t *testing.T) {
+	const D = 400 * time.Millisecond
+
+	// the limit is part of the base strategy
+	cycler := retry.NewCycler(backoff.Limit(backoff.Constant(1*time.Millisecond), 4))
+	cycler.SplitDeadline(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), D)
+	defer cancel()
+
+	err := cycler.Run(ctx, func(ctx context.Context, n int) error {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			t.Fatalf("attempt context has no deadline")
+		}
+		if d := time.Until(deadline); d > D/4 {
+			t.Errorf("deadline in %s, want <= %s", d, D/4)
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCycler_Stagger(t *testing.T) {
+	const D = 1 * time.Hour
+
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Stagger(D)
+
+	var delays []time.Duration
+	cycler.Sleeper = retry.SleeperFunc(func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	})
+
+	for i := 0; i < 10; i++ {
+		_ = cycler.Try(func(n int) error { return nil })
+	}
+
+	if len(delays) != 10 {
+		t.Fatalf("got %d delays, want 10", len(delays))
+	}
+	for i, d := range delays {
+		if d < 0 || d >= D {
+			t.Errorf("#%d: delay %s out of range [0, %s)", i, d, D)
+		}
+	}
+}
+
+func TestCycler_Stagger_Cancel(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Stagger(1 * time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := cycler.TryWithContext(ctx, func(n int) error {
+		t.Fatalf("attempt was executed")
+		return nil
+	})
+
+	if err != context.Canceled {
+		t.Errorf("unexpected error: %#v", err)
+	}
+}
+
+func TestCycler_SkipFirst(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.SkipFirst(true)
+	cycler.Limit(3)
+
+	ds, err := delays(cycler)
+	if !errors.Is(err, ErrTest) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	exp := []time.Duration{0, 1 * time.Millisecond}
+	if !reflect.DeepEqual(ds, exp) {
+		t.Errorf("delays were %v, want %v", ds, exp)
+	}
+}
+
+func TestCycler_InitialDelay(t *testing.T) {
+	const D = 1 * time.Hour
+
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Second))
+	cycler.InitialDelay(D)
+	cycler.Limit(2)
+
+	var delays []time.Duration
+	cycler.Sleeper = retry.SleeperFunc(func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	})
+
+	_ = cycler.Try(func(n int) error { return ErrTest })
+
+	exp := []time.Duration{D, 1 * time.Second}
+	if !reflect.DeepEqual(delays, exp) {
+		t.Errorf("delays were %v, want %v", delays, exp)
+	}
+}
+
+func TestCycler_JitterAfter(t *testing.T) {
+	const D = 1 * time.Millisecond
+
+	cycler := retry.NewCycler(backoff.Constant(D))
+	cycler.JitterAfter(3, 0.5)
+	cycler.Limit(3)
+
+	ds, _ := delays(cycler)
+
+	exp := []time.Duration{D, D}
+	if !reflect.DeepEqual(ds, exp) {
+		t.Errorf("delays were %v, want %v", ds, exp)
+	}
+}
+
+func TestCycler_Redact(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Limit(2)
+	cycler.History(2)
+
+	redacted := errors.New("redacted")
+	cycler.Redact(func(err error) error { return redacted })
+
+	var shown []error
+	cycler.OnError(func(n int, delay time.Duration, err error) {
+		shown = append(shown, err)
+	})
+	cycler.OnFailure(func(f retry.Failure) {
+		shown = append(shown, f.Err)
+	})
+	cycler.OnExit(func(reason retry.StopReason, n int, err error) {
+		shown = append(shown, err)
+	})
+
+	err := cycler.Try(func(n int) error { return ErrTest })
+
+	if !errors.Is(err, ErrTest) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	var e *retry.CycleError
+	if !errors.As(err, &e) {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+	for i, f := range e.History {
+		if f.Err != redacted {
+			t.Errorf("history[%d] not redacted: %v", i, f.Err)
+		}
+	}
+	if len(shown) != 4 {
+		t.Fatalf("got %d errors, want 4", len(shown))
+	}
+	for i, err := range shown {
+		if err != redacted {
+			t.Errorf("#%d: error not redacted: %v", i, err)
+		}
+	}
+}
+
+func TestCycler_Redact_Nil(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Limit(2)
+	cycler.History(2)
+	cycler.Redact(func(err error) error { return nil })
+
+	var shown []error
+	cycler.OnError(func(n int, delay time.Duration, err error) {
+		shown = append(shown, err)
+	})
+
+	err := cycler.Try(func(n int) error { return ErrTest })
+
+	var e *retry.CycleError
+	if !errors.As(err, &e) {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+	for i, f := range e.History {
+		if f.Err != ErrTest {
+			t.Errorf("history[%d] = %v, want %v", i, f.Err, ErrTest)
+		}
+	}
+	for i, err := range shown {
+		if err != ErrTest {
+			t.Errorf("#%d: got %v, want %v", i, err, ErrTest)
+		}
+	}
+	if _, err := json.Marshal(e); err != nil {
+		t.Errorf("marshal: %v", err)
+	}
+}
+
+func TestCycler_PolicyFor(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.PolicyFor(func(ctx context.Context) backoff.Strategy {
+		if ctx.Value(key{}) == "premium" {
+			return backoff.Constant(2 * time.Millisecond)
+		}
+		return nil
+	})
+	cycler.Limit(2)
+
+	var ds []time.Duration
+	cycler.OnError(func(n int, delay time.Duration, err error) {
+		ds = append(ds, delay)
+	})
+
+	ctx := context.WithValue(context.Background(), key{}, "premium")
+	_ = cycler.TryWithContext(ctx, func(n int) error { return ErrTest })
+	_ = cycler.TryWithContext(context.Background(), func(n int) error { return ErrTest })
+
+	exp := []time.Duration{2 * time.Millisecond, 1 * time.Millisecond}
+	if !reflect.DeepEqual(ds, exp) {
+		t.Errorf("delays were %v, want %v", ds, exp)
+	}
+}
+
+func TestCycler_Grace(t *testing.T) {
+	now := time.Now()
+
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Clock = backoff.ClockFunc(func() time.Time { return now })
+	cycler.RetryIf(func(err error) bool { return false })
+	cycler.Grace(10 * time.Second)
+
+	i := 0
+	err := cycler.Try(func(n int) error {
+		i++
+		now = now.Add(4 * time.Second)
+		return ErrTest
+	})
+
+	if err != ErrTest {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if i != 3 {
+		t.Errorf("i = %d, want 3", i)
+	}
+}
+
+func TestCycler_InheritDeadline(t *testing.T) {
+	now := time.Now()
+
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Clock = backoff.ClockFunc(func() time.Time { return now })
+	cycler.InheritDeadline(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Hour)
+	defer cancel()
+
+	i := 0
+	err := cycler.TryWithContext(ctx, func(n int) error {
+		i++
+		now = now.Add(40 * time.Minute)
+		return ErrTest
+	})
+
+	if !errors.Is(err, retry.ErrTimeout) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if i != 2 {
+		t.Errorf("i = %d, want 2", i)
+	}
+}
+
+func TestCycler_StopOn(t *testing.T) {
+	signal := make(chan struct{})
+
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.StopOn(signal)
+
+	var reason retry.StopReason
+	cycler.OnExit(func(r retry.StopReason, n int, err error) { reason = r })
+
+	err := cycler.Try(func(n int) error {
+		if n == 3 {
+			close(signal)
+		}
+		return ErrTest
+	})
+
+	if !errors.Is(err, ErrTest) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if reason != retry.LimitReached {
+		t.Errorf("reason was %s, want %s", reason, retry.LimitReached)
+	}
+}
+
+func TestCycler_SwitchOn(t *testing.T) {
+	errLimited := errors.New("rate limited")
+
+	cycler := retry.NewCycler(backoff.Linear(1*time.Millisecond, 1*time.Millisecond))
+	cycler.SwitchOn(func(err error) backoff.Strategy {
+		if err == errLimited {
+			return backoff.Linear(1*time.Second, 1*time.Second)
+		}
+		return nil
+	})
+	cycler.Limit(5)
+
+	var ds []time.Duration
+	cycler.OnError(func(n int, delay time.Duration, err error) {
+		ds = append(ds, delay)
+	})
+	cycler.Sleeper = retry.SleeperFunc(func(context.Context, time.Duration) error {
+		return nil
+	})
+
+	_ = cycler.Try(func(n int) error {
+		if n == 2 || n == 3 {
+			return errLimited
+		}
+		return ErrTest
+	})
+
+	exp := []time.Duration{
+		1 * time.Millisecond,
+		2 * time.Second,
+		3 * time.Second,
+		4 * time.Millisecond,
+	}
+	if !reflect.DeepEqual(ds, exp) {
+		t.Errorf("delays were %v, want %v", ds, exp)
+	}
+}
+
+func TestCycler_MinAttempts(t *testing.T) {
+	now := time.Now()
+
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Second))
+	cycler.Clock = backoff.ClockFunc(func() time.Time { return now })
+	cycler.Timeout(1 * time.Minute)
+	cycler.MinAttempts(3)
+	cycler.Sleeper = retry.SleeperFunc(func(context.Context, time.Duration) error {
+		return nil
+	})
+
+	i := 0
+	err := cycler.Try(func(n int) error {
+		i++
+		now = now.Add(2 * time.Minute) // slow attempts
+		return ErrTest
+	})
+
+	if !errors.Is(err, retry.ErrTimeout) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if i != 3 {
+		t.Errorf("i = %d, want 3", i)
+	}
+}
+
+func TestCycler_MinAttempts_Run(t *testing.T) {
+	now := time.Now()
+
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Clock = backoff.ClockFunc(func() time.Time { return now })
+	cycler.Timeout(20 * time.Millisecond)
+	cycler.MinAttempts(3)
+
+	var errs []error
+	attempt := func(ctx context.Context, n int) error {
+		errs = append(errs, ctx.Err())
+		now = now.Add(30 * time.Millisecond) // slow attempts
+		return ErrTest
+	}
+	err := cycler.Run(context.Background(), attempt)
+
+	if !errors.Is(err, retry.ErrTimeout) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(errs) != 3 {
+		t.Fatalf("got %d attempts, want 3", len(errs))
+	}
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("#%d: context expired: %v", i+1, err)
+		}
+	}
+}
+
+func TestCycler_BeforeAttempt(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Limit(5)
+
+	var hooks []int
+	cycler.BeforeAttempt(func(ctx context.Context, n int) error {
+		hooks = append(hooks, n)
+		if n == 3 {
+			return ErrFatal
+		}
+		return nil
+	})
+
+	var attempts []int
+	var reason retry.StopReason
+	cycler.OnExit(func(r retry.StopReason, n int, err error) { reason = r })
+
+	err := cycler.Try(func(n int) error {
+		attempts = append(attempts, n)
+		return ErrTest
+	})
+
+	if err != ErrFatal {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if reason != retry.ForcedExit {
+		t.Errorf("reason was %s, want %s", reason, retry.ForcedExit)
+	}
+	if exp := []int{1, 2, 3}; !reflect.DeepEqual(hooks, exp) {
+		t.Errorf("hooks were invoked for %v, want %v", hooks, exp)
+	}
+	if exp := []int{1, 2}; !reflect.DeepEqual(attempts, exp) {
+		t.Errorf("attempts were %v, want %v", attempts, exp)
+	}
+}
+
+func TestCycler_Reason_Stacked(t *testing.T) {
+	now := time.Now()
+
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Clock = backoff.ClockFunc(func() time.Time { return now })
+	cycler.Timeout(90 * time.Second)
+	cycler.Limit(2) // consulted first
+
+	var reason retry.StopReason
+	cycler.OnExit(func(r retry.StopReason, n int, err error) { reason = r })
+
+	err := cycler.Try(func(n int) error {
+		now = now.Add(1 * time.Minute)
+		return ErrTest
+	})
+
+	if !errors.Is(err, retry.ErrLimitExceeded) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if reason != retry.LimitReached {
+		t.Errorf("reason was %s, want %s", reason, retry.LimitReached)
+	}
+}
+
+func TestCycler_Run_Nested(t *testing.T) {
+	outer := retry.NewCycler(backoff.Constant(0))
+	outer.Limit(4)
+	inner := retry.NewCycler(backoff.Constant(0))
+	inner.Limit(10)
+
+	outers, inners := 0, 0
+	err := outer.Run(context.Background(), func(ctx context.Context, n int) error {
+		outers++
+		return inner.Run(ctx, func(ctx context.Context, n int) error {
+			inners++
+			return ErrTest
+		})
+	})
+
+	if !errors.Is(err, ErrTest) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// the inner cycle uses up all three retries of the outer cycle
+	if outers != 1 || inners != 4 {
+		t.Errorf("got %d outer and %d inner attempts, want 1 and 4", outers, inners)
+	}
+}
+
+func TestCycler_TryWithContext_Nested(t *testing.T) {
+	outer := retry.NewCycler(backoff.Constant(0))
+	outer.Limit(4)
+	inner := retry.NewCycler(backoff.Constant(0))
+	inner.Limit(10)
+
+	outers, inners := 0, 0
+	attempt := func(ctx context.Context, n int) error {
+		outers++
+		return inner.TryWithContext(ctx, func(n int) error {
+			inners++
+			return ErrTest
+		})
+	}
+	err := outer.Run(context.Background(), attempt)
+
+	if !errors.Is(err, ErrTest) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// the inner cycle uses up all three retries of the outer cycle
+	if outers != 1 || inners != 4 {
+		t.Errorf("got %d outer and %d inner attempts, want 1 and 4", outers, inners)
+	}
+}
+
+func TestCycler_Run_NestedDeadline(t *testing.T) {
+	now := time.Now()
+
+	outer := retry.NewCycler(backoff.Constant(0))
+	outer.Timeout(1 * time.Hour)
+	inner := retry.NewCycler(backoff.Constant(0))
+	inner.Clock = backoff.ClockFunc(func() time.Time { return now })
+
+	var reason retry.StopReason
+	inner.OnExit(func(r retry.StopReason, n int, err error) { reason = r })
+
+	i := 0
+	_ = outer.Run(context.Background(), func(ctx context.Context, n int) error {
+		err := inner.Run(ctx, func(ctx context.Context, n int) error {
+			i++
+			now = now.Add(40 * time.Minute)
+			return ErrTest
+		})
+		return retry.ForceExit(err)
+	})
+
+	if i != 2 {
+		t.Errorf("got %d inner attempts, want 2", i)
+	}
+	if reason != retry.TimedOut {
+		t.Errorf("reason was %s, want %s", reason, retry.TimedOut)
+	}
+}
+
+func TestCycler_Run_NestedClock(t *testing.T) {
+	now := time.Unix(0, 0) // far from the wall clock
+	clock := backoff.ClockFunc(func() time.Time { return now })
+
+	outer := retry.NewCycler(backoff.Constant(0))
+	outer.Clock = clock
+	outer.Timeout(1 * time.Hour)
+	inner := retry.NewCycler(backoff.Constant(0))
+	inner.Clock = clock
+
+	i := 0
+	_ = outer.Run(context.Background(), func(ctx context.Context, n int) error {
+		err := inner.TryWithContext(ctx, func(n int) error {
+			i++
+			now = now.Add(25 * time.Minute)
+			return ErrTest
+		})
+		return retry.ForceExit(err)
+	})
+
+	if i != 3 {
+		t.Errorf("got %d inner attempts, want 3", i)
+	}
+}
@@ -0,0 +1,269 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retryhttp
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+// HeaderPolicy is the name of the header through which servers hint at the
+// retry policy clients should apply. See [ParsePolicy] for the format.
+const HeaderPolicy = "Retry-Policy"
+
+// ParsePolicy parses a textual retry policy into a [backoff.Strategy]. A policy
+// starts with a base strategy, followed by optional decorators:
+//
+//	const <d>          constant delay d, see [backoff.Constant]
+//	linear <d> <k>     delays growing by k, see [backoff.Linear]
+//	exp <d> x<m>       delays growing by factor m, see [backoff.Exponential]
+//	jitter <spread>    random jitter, see [backoff.Jitter]
+//	max <d>            delay cap, see [backoff.Cap]
+//	limit <n>          attempt limit, see [backoff.Limit]
+//
+// Durations are given in the format accepted by [time.ParseDuration]. For
+// example, "exp 1s x2 max 30s" describes exponential backoff starting at one
+// second, doubling with every retry, capped at 30 seconds. Decorators are
+// applied in the canonical order of [backoff.Builder].
+func ParsePolicy(s string) (backoff.Strategy, error) {
+	fail := func(format string, args ...interface{}) (backoff.Strategy, error) {
+		msg := fmt.Sprintf(format, args...)
+		return nil, fmt.Errorf("retryhttp: invalid policy %q: %s", s, msg)
+	}
+
+	f := strings.Fields(s)
+	if len(f) == 0 {
+		return fail("empty")
+	}
+	// args consumes the next k fields following the keyword at f[i]
+	i := 0
+	args := func(k int) ([]string, bool) {
+		if i+k >= len(f) {
+			return nil, false
+		}
+		a := f[i+1 : i+1+k]
+		i += k + 1
+		return a, true
+	}
+
+	var (
+		strategy backoff.Strategy
+		err      error
+	)
+	switch kw := f[0]; kw {
+	case "const", "constant":
+		a, ok := args(1)
+		if !ok {
+			return fail("missing delay")
+		}
+		d, err := time.ParseDuration(a[0])
+		if err != nil {
+			return fail("%v", err)
+		}
+		strategy, err = backoff.NewConstant(d)
+		if err != nil {
+			return fail("%v", err)
+		}
+	case "lin", "linear":
+		a, ok := args(2)
+		if !ok {
+			return fail("missing delay or slope")
+		}
+		d, err := time.ParseDuration(a[0])
+		if err != nil {
+			return fail("%v", err)
+		}
+		k, err := time.ParseDuration(strings.TrimPrefix(a[1], "+"))
+		if err != nil {
+			return fail("%v", err)
+		}
+		strategy, err = backoff.NewLinear(d, k)
+		if err != nil {
+			return fail("%v", err)
+		}
+	case "exp", "exponential":
+		a, ok := args(2)
+		if !ok {
+			return fail("missing delay or factor")
+		}
+		d, err := time.ParseDuration(a[0])
+		if err != nil {
+			return fail("%v", err)
+		}
+		m, err := strconv.ParseFloat(strings.TrimPrefix(a[1], "x"), 64)
+		if err != nil {
+			return fail("%v", err)
+		}
+		strategy, err = backoff.NewExponential(d, m)
+		if err != nil {
+			return fail("%v", err)
+		}
+	default:
+		return fail("unknown strategy %q", kw)
+	}
+
+	var (
+		spread float64       // jitter spread
+		max    time.Duration // delay cap
+		limit  int           // attempt limit
+	)
+	for i < len(f) {
+		kw := f[i]
+		a, ok := args(1)
+		if !ok {
+			return fail("missing argument for %q", kw)
+		}
+		switch kw {
+		case "jitter":
+			spread, err = strconv.ParseFloat(a[0], 64)
+		case "max":
+			max, err = time.ParseDuration(a[0])
+		case "limit":
+			limit, err = strconv.Atoi(a[0])
+		default:
+			return fail("unknown decorator %q", kw)
+		}
+		if err != nil {
+			return fail("%v", err)
+		}
+	}
+	// apply decorators in the canonical order of backoff.Builder
+	strategy, err = backoff.NewJitter(strategy, spread, rand.Float64)
+	if err != nil {
+		return fail("%v", err)
+	}
+	strategy = backoff.Cap(strategy, max)
+	strategy = backoff.Limit(strategy, limit)
+	return strategy, nil
+}
+
+// Hints keeps track of the retry policies hinted by servers, keyed by
+// endpoint. It is safe for concurrent use. The zero value is ready to use.
+//
+// Since hints are untrusted input, a server could make clients retry in a
+// tight loop, or wait for an arbitrarily long time. The bounds below clamp
+// hinted policies on the client side; they should be set unless the servers
+// are trusted. Bounds that are not positive are not enforced.
+type Hints struct {
+	MinDelay    time.Duration // minimum delay between retries
+	MaxDelay    time.Duration // maximum delay between retries
+	MaxAttempts int           // maximum number of attempts per cycle
+
+	mu       sync.RWMutex
+	policies map[string]backoff.Strategy
+}
+
+// Endpoint returns the key under which the hints of the server that handled
+// req are stored, which consists of the scheme and host of the URL.
+func Endpoint(req *http.Request) string {
+	return req.URL.Scheme + "://" + req.URL.Host
+}
+
+// Observe records the policy hinted by res through [HeaderPolicy], if any,
+// clamped to the bounds of h. Malformed hints are ignored. A response without
+// hint leaves the policy of the endpoint unchanged.
+func (h *Hints) Observe(res *http.Response) {
+	v := res.Header.Get(HeaderPolicy)
+	if v == "" || res.Request == nil {
+		return
+	}
+	s, err := ParsePolicy(v)
+	if err != nil {
+		return
+	}
+	if h.MinDelay > 0 {
+		s = &floor{strategy: s, min: h.MinDelay}
+	}
+	s = backoff.Cap(s, h.MaxDelay)
+	s = backoff.Limit(s, h.MaxAttempts)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.policies == nil {
+		h.policies = make(map[string]backoff.Strategy)
+	}
+	h.policies[Endpoint(res.Request)] = s
+}
+
+// Strategy returns a [backoff.Strategy] that applies the policy most recently
+// hinted for the given endpoint, or fallback if there is none. The policy is
+// looked up anew for every delay, such that hints take effect for subsequent
+// retries right away.
+func (h *Hints) Strategy(
+	endpoint string,
+	fallback backoff.Strategy,
+) backoff.Strategy {
+	return &hinted{hints: h, endpoint: endpoint, fallback: fallback}
+}
+
+func (h *Hints) lookup(endpoint string) backoff.Strategy {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.policies[endpoint]
+}
+
+type hinted struct {
+	hints    *Hints
+	endpoint string
+	fallback backoff.Strategy
+}
+
+func (s *hinted) Delay(n int, start time.Time) time.Duration {
+	delay, _ := s.Explain(n, start)
+	return delay
+}
+
+func (s *hinted) Explain(
+	n int,
+	start time.Time,
+) (time.Duration, backoff.Cause) {
+	if p := s.hints.lookup(s.endpoint); p != nil {
+		return backoff.Explain(p, n, start)
+	}
+	return backoff.Explain(s.fallback, n, start)
+}
+
+// floor is a backoff strategy that raises the delays of another strategy to
+// a minimum.
+type floor struct {
+	strategy backoff.Strategy
+	min      time.Duration
+}
+
+func (f *floor) Delay(n int, start time.Time) time.Duration {
+	delay, _ := f.Explain(n, start)
+	return delay
+}
+
+func (f *floor) Explain(
+	n int,
+	start time.Time,
+) (time.Duration, backoff.Cause) {
+	delay, cause := backoff.Explain(f.strategy, n, start)
+	if delay != backoff.Exit && delay < f.min {
+		return f.min, cause
+	}
+	return delay, cause
+}
+
+func (f *floor) Unwrap() backoff.Strategy { return f.strategy }
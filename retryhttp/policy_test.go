@@ -0,0 +1,146 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retryhttp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+	"github.com/deep-rent/retry/retryhttp"
+)
+
+func TestParsePolicy(t *testing.T) {
+	d := time.Date(0, 0, 0, 0, 0, 0, 0, time.Local)
+	for i, test := range []struct {
+		policy string
+		exp    []time.Duration
+	}{
+		{"const 1s", []time.Duration{1 * time.Second, 1 * time.Second}},
+		{"linear 1s +500ms limit 3", []time.Duration{
+			1 * time.Second, 1500 * time.Millisecond, backoff.Exit,
+		}},
+		{"exp 1s x2 max 3s", []time.Duration{
+			1 * time.Second, 2 * time.Second, 3 * time.Second,
+		}},
+	} {
+		s, err := retryhttp.ParsePolicy(test.policy)
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+			continue
+		}
+		for j, exp := range test.exp {
+			if act := s.Delay(j+1, d); act != exp {
+				t.Errorf("#%d: delay #%d was %s, want %s", i, j+1, act, exp)
+			}
+		}
+	}
+}
+
+func TestParsePolicy_Invalid(t *testing.T) {
+	for _, policy := range []string{
+		"",
+		"poly 1s",
+		"const",
+		"const x",
+		"exp 1s",
+		"exp 1s 2s",
+		"const 1s max",
+		"const 1s retries 3",
+		"const 1s jitter 2",
+		"const -1s",
+		"exp 1s x-2",
+		"linear -1s +1s",
+	} {
+		if _, err := retryhttp.ParsePolicy(policy); err == nil {
+			t.Errorf("expected error for %q", policy)
+		}
+	}
+}
+
+func TestHints(t *testing.T) {
+	var h retryhttp.Hints
+	fallback := backoff.Constant(5 * time.Second)
+	s := h.Strategy("http://example.com", fallback)
+
+	if act := s.Delay(1, time.Now()); act != 5*time.Second {
+		t.Errorf("delay was %s, want fallback", act)
+	}
+
+	res := &http.Response{
+		Header:  http.Header{},
+		Request: httptest.NewRequest(http.MethodGet, "http://example.com/a", nil),
+	}
+	res.Header.Set(retryhttp.HeaderPolicy, "const 1s")
+	h.Observe(res)
+
+	if act := s.Delay(1, time.Now()); act != 1*time.Second {
+		t.Errorf("delay was %s, want hinted delay", act)
+	}
+
+	// malformed hints are ignored
+	res.Header.Set(retryhttp.HeaderPolicy, "nonsense")
+	h.Observe(res)
+
+	if act := s.Delay(1, time.Now()); act != 1*time.Second {
+		t.Errorf("delay was %s, want hinted delay", act)
+	}
+
+	// other endpoints are not affected
+	other := h.Strategy("https://example.com", fallback)
+	if act := other.Delay(1, time.Now()); act != 5*time.Second {
+		t.Errorf("delay was %s, want fallback", act)
+	}
+}
+
+func TestHints_Bounds(t *testing.T) {
+	h := retryhttp.Hints{
+		MinDelay:    100 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+		MaxAttempts: 3,
+	}
+	s := h.Strategy("http://example.com", backoff.Constant(time.Second))
+
+	res := &http.Response{
+		Header:  http.Header{},
+		Request: httptest.NewRequest(http.MethodGet, "http://example.com/a", nil),
+	}
+	for i, test := range []struct {
+		policy string
+		exp    []time.Duration
+	}{
+		{"const 0s", []time.Duration{
+			100 * time.Millisecond, 100 * time.Millisecond, backoff.Exit,
+		}},
+		{"const 1h limit 10", []time.Duration{
+			10 * time.Second, 10 * time.Second, backoff.Exit,
+		}},
+		{"const 1s limit 2", []time.Duration{
+			1 * time.Second, backoff.Exit,
+		}},
+	} {
+		res.Header.Set(retryhttp.HeaderPolicy, test.policy)
+		h.Observe(res)
+		for j, exp := range test.exp {
+			if act := s.Delay(j+1, time.Now()); act != exp {
+				t.Errorf("#%d: delay #%d was %s, want %s", i, j+1, act, exp)
+			}
+		}
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package retryhttp integrates retry policies with HTTP clients and servers.
+package retryhttp
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+// An Identify function returns the identity of the client that issued r.
+type Identify func(r *http.Request) string
+
+// RemoteHost identifies clients by the host part of their remote address.
+func RemoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// client tracks the load shedding episode of a single client.
+type client struct {
+	n     int       // number of consecutive rejections
+	start time.Time // time of the first rejection
+	last  time.Time // time of the most recent rejection
+}
+
+// A Shedder is an HTTP middleware that advises rejected clients when to retry.
+// Whenever the wrapped handler sheds load by responding with status 429 (Too
+// Many Requests) or 503 (Service Unavailable), the shedder sets the
+// Retry-After header to the delay the backoff strategy produces for the
+// number of consecutive rejections of that client, rounded up to full seconds.
+// This way, the same policy vocabulary governs both the client and the server
+// side of backpressure. Any other status ends the episode of the client. The
+// header is left untouched if already set by the handler, or if the strategy
+// returns [backoff.Exit].
+//
+// Episodes that see no rejections for the duration of the idle period are
+// forgotten. A shedder is safe for concurrent use. Use [NewShedder] to create
+// a new instance.
+type Shedder struct {
+	strategy backoff.Strategy
+	identify Identify
+	idle     time.Duration
+	mu       sync.Mutex // guards the fields below
+	clients  map[string]*client
+	swept    time.Time // time of the last sweep
+}
+
+// NewShedder creates a new [Shedder] that computes delays based on strategy.
+// Clients are told apart by identify; if nil, [RemoteHost] is used. Episodes
+// are forgotten after the given idle period, which defaults to 10 minutes if
+// idle <= 0.
+func NewShedder(
+	strategy backoff.Strategy,
+	identify Identify,
+	idle time.Duration,
+) *Shedder {
+	if identify == nil {
+		identify = RemoteHost
+	}
+	if idle <= 0 {
+		idle = 10 * time.Minute
+	}
+	return &Shedder{
+		strategy: strategy,
+		identify: identify,
+		idle:     idle,
+		clients:  make(map[string]*client),
+	}
+}
+
+// Wrap returns a handler that executes next and sets the Retry-After header
+// on rejections.
+func (s *Shedder) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&recorder{
+			ResponseWriter: w,
+			shedder:        s,
+			id:             s.identify(r),
+		}, r)
+	})
+}
+
+// reject records a rejection of the client identified by id, and returns the
+// delay after which the client should retry.
+func (s *Shedder) reject(id string) time.Duration {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+	c := s.clients[id]
+	if c == nil || now.Sub(c.last) >= s.idle {
+		c = &client{start: now}
+		s.clients[id] = c
+	}
+	c.n++
+	c.last = now
+	return s.strategy.Delay(c.n, c.start)
+}
+
+// accept ends the episode of the client identified by id.
+func (s *Shedder) accept(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.clients, id)
+}
+
+// sweep forgets idle episodes, at most once per idle period.
+func (s *Shedder) sweep(now time.Time) {
+	if now.Sub(s.swept) < s.idle {
+		return
+	}
+	s.swept = now
+	for id, c := range s.clients {
+		if now.Sub(c.last) >= s.idle {
+			delete(s.clients, id)
+		}
+	}
+}
+
+// recorder intercepts the status code written by a handler.
+type recorder struct {
+	http.ResponseWriter
+	shedder *Shedder
+	id      string // identity of the client
+	written bool   // whether the header was written
+}
+
+func (r *recorder) WriteHeader(code int) {
+	if r.written {
+		r.ResponseWriter.WriteHeader(code)
+		return
+	}
+	r.written = true
+	switch code {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		delay := r.shedder.reject(r.id)
+		h := r.Header()
+		if delay != backoff.Exit && h.Get("Retry-After") == "" {
+			secs := (delay + time.Second - 1) / time.Second
+			h.Set("Retry-After", strconv.FormatInt(int64(secs), 10))
+		}
+	default:
+		r.shedder.accept(r.id)
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	if !r.written {
+		r.WriteHeader(http.StatusOK)
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying [http.ResponseWriter].
+func (r *recorder) Unwrap() http.ResponseWriter { return r.ResponseWriter }
@@ -0,0 +1,93 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retryhttp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+	"github.com/deep-rent/retry/retryhttp"
+)
+
+func TestShedder(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	s := retryhttp.NewShedder(
+		backoff.Limit(backoff.Linear(1*time.Second, 1500*time.Millisecond), 3),
+		nil, 0,
+	)
+	h := s.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+
+	serve := func(addr string) string {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = addr
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != status {
+			t.Fatalf("status was %d, want %d", w.Code, status)
+		}
+		return w.Header().Get("Retry-After")
+	}
+
+	for i, exp := range []string{"1", "3", ""} {
+		if act := serve("10.0.0.1:1234"); act != exp {
+			t.Errorf("#%d: Retry-After was %q, want %q", i, act, exp)
+		}
+	}
+	// other clients are tracked separately
+	if act := serve("10.0.0.2:1234"); act != "1" {
+		t.Errorf("Retry-After was %q, want %q", act, "1")
+	}
+
+	// success ends the episode
+	status = http.StatusOK
+	if act := serve("10.0.0.1:5678"); act != "" {
+		t.Errorf("Retry-After was %q, want none", act)
+	}
+	status = http.StatusTooManyRequests
+	if act := serve("10.0.0.1:5678"); act != "1" {
+		t.Errorf("Retry-After was %q, want %q", act, "1")
+	}
+}
+
+func TestShedder_Preset(t *testing.T) {
+	s := retryhttp.NewShedder(backoff.Constant(1*time.Second), nil, 0)
+	h := s.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "42")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if act := w.Header().Get("Retry-After"); act != "42" {
+		t.Errorf("Retry-After was %q, want %q", act, "42")
+	}
+}
+
+func TestRemoteHost(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "[::1]:80"
+
+	if act := retryhttp.RemoteHost(r); act != "::1" {
+		t.Errorf("host was %q, want %q", act, "::1")
+	}
+}
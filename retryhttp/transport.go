@@ -0,0 +1,322 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retryhttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/deep-rent/retry"
+)
+
+// errStatus signals that a response carried a retryable status code.
+var errStatus = errors.New("retryhttp: retryable status")
+
+// A Transport is an [http.RoundTripper] that retries idempotent requests in
+// retry cycles scheduled by a [retry.Cycler], and optionally hedges GET
+// requests: if no response arrived after a delay, a duplicate request is sent,
+// and whichever succeeds first wins, while the other one is cancelled. Hedging
+// cuts tail latency, whereas retries cover the ultimate failure case.
+//
+// Requests are retried if the round trip fails, or if the response carries
+// status 429, 502, 503 or 504, as long as the method is idempotent and the
+// body can be replayed (see [http.Request.GetBody]). If the cycle gives up
+// after a retryable status, the last response is returned as is, unless the
+// context of the request is done, in which case its error is returned.
+//
+// If Targets is set, each attempt is dialed at the address it supplies in the
+// form "host:port", such that retries rotate through the replicas of a service
+// (see [retry.RoundRobin] and [retry.Lookup]). Otherwise, the targets supplied
+// through [retry.Cycler.Rotate] are used. Only the dial address changes: the
+// URL, the Host header, and the server name used for TLS verification keep the
+// host of the original request. Targets bypass any proxy, and take effect only
+// if Next is an [*http.Transport]. Connections are pooled per target for up to
+// a fixed number of targets, beyond which the pools of the least recently
+// added targets are closed.
+type Transport struct {
+	Next    http.RoundTripper // defaults to http.DefaultTransport
+	Cycler  *retry.Cycler     // schedules retries; nil disables retries
+	Hedge   time.Duration     // delay before hedging GETs; 0 disables hedging
+	Targets retry.TargetFunc  // dial address of each attempt; optional
+
+	mu     sync.Mutex
+	routes map[string]*http.Transport // clones of Next by dial address
+	order  []string                   // dial addresses in order of addition
+}
+
+// maxRoutes is the maximum number of targets a [Transport] pools connections
+// for.
+const maxRoutes = 64
+
+// RoundTrip implements [http.RoundTripper].
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.Cycler == nil || !idempotent(req) || !replayable(req) {
+		return t.hedge(t.route(req, 1), req)
+	}
+	var last *http.Response // last response received
+	err := t.Cycler.TryWithContext(req.Context(), func(n int) error {
+		if last != nil {
+			discard(last)
+			last = nil
+		}
+		r := req
+		if n > 1 && hasBody(req) {
+			body, err := req.GetBody()
+			if err != nil {
+				return retry.ForceExit(err)
+			}
+			r = req.Clone(req.Context())
+			r.Body = body
+		}
+		res, err := t.hedge(t.route(r, n), r)
+		if err != nil {
+			return err
+		}
+		last = res
+		if retryable(res.StatusCode) {
+			return errStatus
+		}
+		return nil
+	})
+	if last != nil && err != nil {
+		// the cycle gave up after a retryable status
+		if cerr := req.Context().Err(); cerr != nil {
+			discard(last)
+			return nil, cerr
+		}
+	}
+	if last != nil {
+		return last, nil
+	}
+	return nil, err
+}
+
+// CloseIdleConnections closes the idle connections of the underlying transport,
+// and of the transports dialing the targets, if the transports support it.
+func (t *Transport) CloseIdleConnections() {
+	type closeIdler interface{ CloseIdleConnections() }
+	if c, ok := t.next().(closeIdler); ok {
+		c.CloseIdleConnections()
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, rt := range t.routes {
+		rt.CloseIdleConnections()
+	}
+}
+
+// route returns the transport for the n-th attempt of req, which dials the
+// target supplied by t.Targets or t.Cycler, if any.
+func (t *Transport) route(req *http.Request, n int) http.RoundTripper {
+	next := t.next()
+	base, ok := next.(*http.Transport)
+	if !ok {
+		return next
+	}
+	var target string
+	if t.Targets != nil {
+		target = t.Targets(req.Context(), n)
+	} else if t.Cycler != nil {
+		target = t.Cycler.Target(req.Context(), n)
+	}
+	if target == "" {
+		return next
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if rt, ok := t.routes[target]; ok {
+		return rt
+	}
+	// a clone per target keeps the connection pools apart
+	rt := base.Clone()
+	rt.Proxy = nil
+	dial := base.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	rt.DialContext = func(
+		ctx context.Context,
+		network, _ string,
+	) (net.Conn, error) {
+		return dial(ctx, network, target)
+	}
+	if dialTLS := base.DialTLSContext; dialTLS != nil {
+		rt.DialTLSContext = func(
+			ctx context.Context,
+			network, _ string,
+		) (net.Conn, error) {
+			return dialTLS(ctx, network, target)
+		}
+	}
+	if t.routes == nil {
+		t.routes = make(map[string]*http.Transport)
+	}
+	if len(t.order) == maxRoutes {
+		// evict the oldest target; requests in flight complete regardless
+		t.routes[t.order[0]].CloseIdleConnections()
+		delete(t.routes, t.order[0])
+		t.order = t.order[1:]
+	}
+	t.routes[target] = rt
+	t.order = append(t.order, target)
+	return rt
+}
+
+// next returns the underlying transport.
+func (t *Transport) next() http.RoundTripper {
+	if t.Next == nil {
+		return http.DefaultTransport
+	}
+	return t.Next
+}
+
+// hedge sends req through rt, and a duplicate if hedging applies and no
+// response arrived in time. It returns the first successful round trip, or the
+// last error if all round trips failed.
+func (t *Transport) hedge(
+	rt http.RoundTripper,
+	req *http.Request,
+) (*http.Response, error) {
+	if t.Hedge <= 0 || req.Method != http.MethodGet || !replayable(req) {
+		return rt.RoundTrip(req)
+	}
+
+	type result struct {
+		i   int // index of the round trip
+		res *http.Response
+		err error
+	}
+	results := make(chan result, 2)
+	var cancels []context.CancelFunc
+	send := func(body io.ReadCloser) {
+		ctx, cancel := context.WithCancel(req.Context())
+		r := req.Clone(ctx)
+		r.Body = body
+		i := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			res, err := rt.RoundTrip(r)
+			results <- result{i, res, err}
+		}()
+	}
+
+	send(req.Body)
+	pending := 1
+	timer := time.NewTimer(t.Hedge)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			var body io.ReadCloser
+			if hasBody(req) {
+				var err error
+				if body, err = req.GetBody(); err != nil {
+					continue // keep waiting for the first round trip
+				}
+			}
+			send(body)
+			pending++
+		case r := <-results:
+			pending--
+			if r.err != nil {
+				cancels[r.i]()
+				if pending == 0 {
+					return nil, r.err
+				}
+				continue
+			}
+			for i, cancel := range cancels {
+				if i != r.i {
+					cancel() // the loser
+				}
+			}
+			if pending > 0 {
+				go func() {
+					if r := <-results; r.res != nil {
+						discard(r.res)
+					}
+				}()
+			}
+			// release the context of the winner once its body is closed
+			r.res.Body = &cancelBody{
+				ReadCloser: r.res.Body,
+				cancel:     cancels[r.i],
+			}
+			return r.res, nil
+		}
+	}
+}
+
+// cancelBody cancels the context of a request once its response body is
+// closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// idempotent reports whether the method of req is idempotent.
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions,
+		http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// hasBody reports whether req has a non-empty body.
+func hasBody(req *http.Request) bool {
+	return req.Body != nil && req.Body != http.NoBody
+}
+
+// replayable reports whether the body of req can be sent again.
+func replayable(req *http.Request) bool {
+	return !hasBody(req) || req.GetBody != nil
+}
+
+// retryable reports whether a response with the given status code is worth
+// retrying.
+func retryable(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// discard drains and closes the body of res, such that the underlying
+// connection can be reused.
+func discard(res *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 4<<10))
+	_ = res.Body.Close()
+}
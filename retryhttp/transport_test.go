@@ -0,0 +1,253 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retryhttp_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+	"github.com/deep-rent/retry/retryhttp"
+)
+
+func TestTransport_Retry(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Limit(5)
+	client := &http.Client{Transport: &retryhttp.Transport{Cycler: cycler}}
+
+	res, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("status was %d, want %d", res.StatusCode, http.StatusOK)
+	}
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Errorf("got %d calls, want 3", n)
+	}
+}
+
+func TestTransport_Retry_Exhausted(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Limit(2)
+	client := &http.Client{Transport: &retryhttp.Transport{Cycler: cycler}}
+
+	res, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status was %d, want %d", res.StatusCode, http.StatusServiceUnavailable)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("got %d calls, want 2", n)
+	}
+}
+
+// roundTripFunc is an http.RoundTripper backed by a function.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestTransport_Retry_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	next := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		cancel() // the context ends right after the response arrived
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Body:       http.NoBody,
+			Request:    r,
+		}, nil
+	})
+
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Second))
+	cycler.Limit(2)
+	tr := &retryhttp.Transport{Next: next, Cycler: cycler}
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com/", nil)
+	res, err := tr.RoundTrip(req)
+	if err == nil {
+		t.Fatalf("status was %d, want error", res.StatusCode)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// idler is an http.RoundTripper that counts calls to CloseIdleConnections.
+type idler struct {
+	http.RoundTripper
+	calls int
+}
+
+func (i *idler) CloseIdleConnections() { i.calls++ }
+
+func TestTransport_CloseIdleConnections(t *testing.T) {
+	next := &idler{RoundTripper: http.DefaultTransport}
+	client := &http.Client{Transport: &retryhttp.Transport{Next: next}}
+	client.CloseIdleConnections()
+
+	if next.calls != 1 {
+		t.Errorf("got %d calls, want 1", next.calls)
+	}
+}
+
+func TestTransport_Hedge(t *testing.T) {
+	var calls int32
+	cancelled := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// the first request hangs until it is cancelled
+			<-r.Context().Done()
+			close(cancelled)
+			return
+		}
+		_, _ = io.WriteString(w, "hedged")
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &retryhttp.Transport{Hedge: 10 * time.Millisecond}}
+
+	res, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+
+	if string(body) != "hedged" {
+		t.Errorf("body was %q, want %q", body, "hedged")
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(1 * time.Second):
+		t.Error("the losing request was not cancelled")
+	}
+}
+
+func TestTransport_Targets(t *testing.T) {
+	dead := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer dead.Close()
+	var host atomic.Value
+	alive := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host.Store(r.Host)
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer alive.Close()
+
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Limit(3)
+	client := &http.Client{Transport: &retryhttp.Transport{
+		Cycler:  cycler,
+		Targets: retry.RoundRobin(dead.Listener.Addr().String(), alive.Listener.Addr().String()),
+	}}
+
+	res, err := client.Get("http://example.com/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("status was %d, want %d", res.StatusCode, http.StatusOK)
+	}
+	if act := host.Load(); act != "example.com" {
+		t.Errorf("host was %v, want example.com", act)
+	}
+}
+
+func TestTransport_Targets_TLS(t *testing.T) {
+	var name atomic.Value
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name.Store(r.TLS.ServerName)
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	// the certificate of the test server is valid for example.com
+	client := &http.Client{Transport: &retryhttp.Transport{
+		Next:    srv.Client().Transport,
+		Targets: retry.RoundRobin(srv.Listener.Addr().String()),
+	}}
+
+	res, err := client.Get("https://example.com/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer res.Body.Close()
+
+	if act := name.Load(); act != "example.com" {
+		t.Errorf("server name was %v, want example.com", act)
+	}
+}
+
+func TestTransport_Rotate(t *testing.T) {
+	var host atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host.Store(r.Host)
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Limit(1)
+	cycler.Rotate(retry.RoundRobin(srv.Listener.Addr().String()))
+	client := &http.Client{Transport: &retryhttp.Transport{Cycler: cycler}}
+
+	res, err := client.Get("http://example.com/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer res.Body.Close()
+
+	if act := host.Load(); act != "example.com" {
+		t.Errorf("host was %v, want example.com", act)
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retryhttp
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+const (
+	// HeaderRateLimitReset is the name of the standardized header through
+	// which servers announce the number of seconds until the current
+	// rate-limit window resets.
+	HeaderRateLimitReset = "RateLimit-Reset"
+	// HeaderXRateLimitReset is the name of the widespread, non-standard header
+	// through which servers announce when the current rate-limit window resets,
+	// either in seconds from now, or as a Unix timestamp.
+	HeaderXRateLimitReset = "X-RateLimit-Reset"
+)
+
+// epoch separates Unix timestamps from relative values in reset headers. No
+// server uses windows anywhere near 30 years long.
+const epoch = 1e9
+
+// A Window tracks the rate-limit windows of a server, which are expected to
+// start every size, beginning at the last reset announced by the server. It is
+// safe for concurrent use. Use [NewWindow] to create a new window.
+//
+// The Clock of a window determines the current time, both when observing
+// resets and when aligning delays. If nil, the system clock is used.
+type Window struct {
+	Clock backoff.Clock
+	mu    sync.Mutex
+	size  time.Duration // length of a window
+	reset time.Time     // start of a window, zero if unknown
+}
+
+// NewWindow creates a new [Window] of the given size. Until a reset is
+// observed, windows are assumed to start at multiples of size since the zero
+// time, which aligns them with the wall clock for common sizes such as a
+// minute or an hour. The function panics if size <= 0.
+func NewWindow(size time.Duration) *Window {
+	if size <= 0 {
+		panic(fmt.Sprintf("size = %s, must be > 0", size))
+	}
+	return &Window{size: size}
+}
+
+// clock returns the Clock of w, or the system clock if there is none.
+func (w *Window) clock() backoff.Clock {
+	if w.Clock == nil {
+		return backoff.ClockFunc(time.Now)
+	}
+	return w.Clock
+}
+
+// Observe records the reset announced by res through [HeaderRateLimitReset] or
+// [HeaderXRateLimitReset], if any. Malformed values are ignored. Since windows
+// repeat, a reset announced more than one window ahead or behind is moved by
+// whole windows, such that aligned delays exceed the delays of the wrapped
+// strategy by less than one window, no matter how far off the server claims
+// the reset to be.
+func (w *Window) Observe(res *http.Response) {
+	v := res.Header.Get(HeaderRateLimitReset)
+	if v == "" {
+		v = res.Header.Get(HeaderXRateLimitReset)
+	}
+	secs, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+	if err != nil || secs < 0 {
+		return
+	}
+	now := w.clock().Time()
+	var reset time.Time
+	if secs >= epoch {
+		reset = time.Unix(secs, 0)
+	} else {
+		reset = now.Add(time.Duration(secs) * time.Second)
+	}
+	ahead := reset.Sub(now)
+	if ahead == math.MaxInt64 || ahead == math.MinInt64 {
+		return // too far off to be represented
+	}
+	// move the reset to within one window of now
+	reset = reset.Add(-(ahead / w.size) * w.size)
+	w.mu.Lock()
+	w.reset = reset
+	w.mu.Unlock()
+}
+
+// next returns the start of the first window beginning at or after t.
+func (w *Window) next(t time.Time) time.Time {
+	w.mu.Lock()
+	reset := w.reset
+	w.mu.Unlock()
+	if reset.IsZero() {
+		b := t.Truncate(w.size)
+		if b.Before(t) {
+			b = b.Add(w.size)
+		}
+		return b
+	}
+	d := t.Sub(reset)
+	k := d / w.size
+	if d%w.size > 0 {
+		k++
+	}
+	return reset.Add(k * w.size)
+}
+
+// Align wraps a backoff [backoff.Strategy] to postpone each retry to the start
+// of the next rate-limit window, such that retries do not land in the middle
+// of an exhausted window, only to be throttled again. Delays that already end
+// on a window boundary remain unchanged.
+func (w *Window) Align(strategy backoff.Strategy) backoff.Strategy {
+	return &aligned{window: w, strategy: strategy}
+}
+
+type aligned struct {
+	window   *Window
+	strategy backoff.Strategy
+}
+
+func (s *aligned) Delay(n int, start time.Time) time.Duration {
+	delay, _ := s.Explain(n, start)
+	return delay
+}
+
+func (s *aligned) Explain(
+	n int,
+	start time.Time,
+) (time.Duration, backoff.Cause) {
+	delay, cause := backoff.Explain(s.strategy, n, start)
+	if delay == backoff.Exit {
+		return backoff.Exit, cause
+	}
+	now := s.window.clock().Time()
+	return s.window.next(now.Add(delay)).Sub(now), cause
+}
+
+func (s *aligned) Unwrap() backoff.Strategy { return s.strategy }
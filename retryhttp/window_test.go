@@ -0,0 +1,124 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retryhttp_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+	"github.com/deep-rent/retry/retryhttp"
+)
+
+// near reports whether d is within one second of exp.
+func near(d, exp time.Duration) bool {
+	return d > exp-time.Second && d <= exp+time.Second
+}
+
+func TestWindow_Align(t *testing.T) {
+	w := retryhttp.NewWindow(1 * time.Minute)
+	res := &http.Response{Header: http.Header{}}
+	res.Header.Set(retryhttp.HeaderRateLimitReset, "10")
+	w.Observe(res)
+
+	tests := []struct {
+		delay time.Duration
+		exp   time.Duration
+	}{
+		{3 * time.Second, 10 * time.Second},
+		{15 * time.Second, 70 * time.Second},
+		{100 * time.Second, 130 * time.Second},
+	}
+
+	for _, test := range tests {
+		s := w.Align(backoff.Constant(test.delay))
+		if act := s.Delay(1, time.Now()); !near(act, test.exp) {
+			t.Errorf("delay %s was aligned to %s, want about %s", test.delay, act, test.exp)
+		}
+	}
+}
+
+func TestWindow_Align_Timestamp(t *testing.T) {
+	w := retryhttp.NewWindow(1 * time.Hour)
+	reset := time.Now().Add(30 * time.Second).Unix()
+	res := &http.Response{Header: http.Header{}}
+	res.Header.Set(retryhttp.HeaderXRateLimitReset, strconv.FormatInt(reset, 10))
+	w.Observe(res)
+
+	s := w.Align(backoff.Constant(1 * time.Second))
+	if act := s.Delay(1, time.Now()); !near(act, 30*time.Second) {
+		t.Errorf("delay was %s, want about 30s", act)
+	}
+}
+
+func TestWindow_Align_Unknown(t *testing.T) {
+	w := retryhttp.NewWindow(1 * time.Minute)
+
+	s := w.Align(backoff.Constant(1 * time.Second))
+	act := s.Delay(1, time.Now())
+	if at := time.Now().Add(act); act < time.Second || act > time.Minute+time.Second ||
+		!near(at.Sub(at.Truncate(time.Minute)), 0) {
+		t.Errorf("delay was %s, want alignment to the next minute", act)
+	}
+}
+
+func TestWindow_Align_Exit(t *testing.T) {
+	w := retryhttp.NewWindow(1 * time.Minute)
+
+	s := w.Align(backoff.Limit(backoff.Constant(1*time.Second), 1))
+	if act := s.Delay(1, time.Now()); act != backoff.Exit {
+		t.Errorf("delay was %s, want exit", act)
+	}
+}
+
+func TestWindow_Clock(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	w := retryhttp.NewWindow(1 * time.Minute)
+	w.Clock = backoff.ClockFunc(func() time.Time { return now })
+
+	res := &http.Response{Header: http.Header{}}
+	res.Header.Set(retryhttp.HeaderRateLimitReset, "10")
+	w.Observe(res)
+	now = now.Add(5 * time.Second)
+
+	s := w.Align(backoff.Constant(1 * time.Second))
+	if act, exp := s.Delay(1, now), 5*time.Second; act != exp {
+		t.Errorf("delay was %s, want %s", act, exp)
+	}
+}
+
+func TestWindow_Align_FarReset(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, v := range []string{
+		strconv.FormatInt(now.Add(24*time.Hour+10*time.Second).Unix(), 10),
+		"999999999",
+		"9223372036854775807",
+	} {
+		w := retryhttp.NewWindow(1 * time.Minute)
+		w.Clock = backoff.ClockFunc(func() time.Time { return now })
+		res := &http.Response{Header: http.Header{}}
+		res.Header.Set(retryhttp.HeaderXRateLimitReset, v)
+		w.Observe(res)
+
+		s := w.Align(backoff.Constant(1 * time.Second))
+		if act := s.Delay(1, now); act < time.Second || act > time.Minute {
+			t.Errorf("%s: delay was %s, want at most one window", v, act)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package retrysql retries database transactions that fail due to transient
+// conflicts, such as serialization failures and deadlocks.
+package retrysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+// SQLSTATE codes of transient transaction conflicts.
+const (
+	SerializationFailure = "40001" // concurrent update under strict isolation
+	DeadlockDetected     = "40P01" // deadlock detected by the server
+)
+
+// SQLState extracts the SQLSTATE code from err, if the driver exposes it
+// through a method SQLState() string, as common PostgreSQL drivers do. It
+// returns an empty string otherwise.
+func SQLState(err error) string {
+	var e interface{ SQLState() string }
+	if errors.As(err, &e) {
+		return e.SQLState()
+	}
+	return ""
+}
+
+// Deadlock reports whether err signals a deadlock. Errors of drivers that do
+// not expose the SQLSTATE code are recognized by their message.
+func Deadlock(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch SQLState(err) {
+	case DeadlockDetected:
+		return true
+	case "":
+		return strings.Contains(strings.ToLower(err.Error()), "deadlock")
+	default:
+		return false
+	}
+}
+
+// SerializationConflict reports whether err signals a serialization failure,
+// or a deadlock. Errors of drivers that do not expose the SQLSTATE code are
+// recognized by their message.
+func SerializationConflict(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch SQLState(err) {
+	case SerializationFailure:
+		return true
+	case "":
+		msg := strings.ToLower(err.Error())
+		if strings.Contains(msg, "could not serialize") ||
+			strings.Contains(msg, "serialization failure") {
+			return true
+		}
+	}
+	return Deadlock(err)
+}
+
+// Classify returns the default [retry.Classifier] for transactions with the
+// given isolation level. Under strict isolation, that is, repeatable read,
+// snapshot, serializable or linearizable, serialization failures are expected
+// and retried along with deadlocks (see [SerializationConflict]). Under weaker
+// isolation, only deadlocks are retried (see [Deadlock]).
+func Classify(level sql.IsolationLevel) retry.Classifier {
+	switch level {
+	case sql.LevelRepeatableRead, sql.LevelSnapshot,
+		sql.LevelSerializable, sql.LevelLinearizable:
+		return SerializationConflict
+	default:
+		return Deadlock
+	}
+}
+
+// A TxFunc performs the statements of a transaction.
+type TxFunc func(tx *sql.Tx) error
+
+// A ReplayFunc checks whether it is safe to replay a transaction after it
+// failed with err, before the n-th attempt starts. Returning an error aborts
+// the retry cycle with that error. Typical checks ensure that no side effects
+// outside of the database happened, such as messages sent.
+type ReplayFunc func(ctx context.Context, n int, err error) error
+
+// A Runner runs transactions in retry cycles. The zero value is ready to use,
+// and applies the defaults described for each field.
+type Runner struct {
+	// Cycler schedules the retry cycles. If nil, up to five attempts are made
+	// with exponential backoff starting at 10ms, capped at one second, with
+	// jitter.
+	Cycler *retry.Cycler
+	// Retryable decides which errors are retried. If nil, the classifier
+	// returned by [Classify] for the isolation level of the transaction is
+	// used.
+	Retryable retry.Classifier
+	// Replay is consulted before each retry, if not nil.
+	Replay ReplayFunc
+}
+
+// fallback is the cycler used by runners without a cycler.
+var fallback = func() *retry.Cycler {
+	c := retry.NewCycler(backoff.Exponential(10*time.Millisecond, 2))
+	c.Jitter(0.5)
+	c.Cap(1 * time.Second)
+	c.Limit(5)
+	return c
+}()
+
+// InTx runs fn within a transaction that begins with the given options, and
+// commits if fn returns nil, or rolls back otherwise. Each attempt runs in a
+// fresh transaction. Failures of fn or of the commit are retried if they are
+// classified as retryable; other errors are returned right away. Hence, fn
+// must be safe to replay, and must not keep state across attempts.
+func (r *Runner) InTx(
+	ctx context.Context,
+	db *sql.DB,
+	opts *sql.TxOptions,
+	fn TxFunc,
+) error {
+	c := r.Cycler
+	if c == nil {
+		c = fallback
+	}
+	retryable := r.Retryable
+	if retryable == nil {
+		level := sql.LevelDefault
+		if opts != nil {
+			level = opts.Isolation
+		}
+		retryable = Classify(level)
+	}
+	var last error // error of the previous attempt
+	return c.TryWithContext(ctx, func(n int) error {
+		if n > 1 && r.Replay != nil {
+			if err := r.Replay(ctx, n, last); err != nil {
+				return retry.ForceExit(err)
+			}
+		}
+		last = run(ctx, db, opts, fn)
+		if last != nil && !retryable(last) {
+			return retry.ForceExit(last)
+		}
+		return last
+	})
+}
+
+// run executes fn in a single transaction.
+func run(
+	ctx context.Context,
+	db *sql.DB,
+	opts *sql.TxOptions,
+	fn TxFunc,
+) error {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// InTx runs fn within a transaction using a zero [Runner]. See [Runner.InTx]
+// for details.
+func InTx(
+	ctx context.Context,
+	db *sql.DB,
+	opts *sql.TxOptions,
+	fn TxFunc,
+) error {
+	var r Runner
+	return r.InTx(ctx, db, opts, fn)
+}
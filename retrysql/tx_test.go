@@ -0,0 +1,211 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retrysql_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+	"github.com/deep-rent/retry/retrysql"
+)
+
+// stateError is a driver error that exposes its SQLSTATE code.
+type stateError string
+
+func (e stateError) Error() string    { return "sqlstate " + string(e) }
+func (e stateError) SQLState() string { return string(e) }
+
+// fake is a database driver that records transactions, and fails commits
+// with the errors queued in commits.
+type fake struct {
+	mu        sync.Mutex
+	commits   []error
+	committed int
+	rolled    int
+}
+
+func (d *fake) Open(string) (driver.Conn, error) { return &conn{d}, nil }
+
+type conn struct{ d *fake }
+
+func (c *conn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *conn) Close() error                        { return nil }
+func (c *conn) Begin() (driver.Tx, error)           { return &tx{c.d}, nil }
+
+func (c *conn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return &tx{c.d}, nil
+}
+
+type tx struct{ d *fake }
+
+func (t *tx) Commit() error {
+	t.d.mu.Lock()
+	defer t.d.mu.Unlock()
+	if len(t.d.commits) > 0 {
+		err := t.d.commits[0]
+		t.d.commits = t.d.commits[1:]
+		if err != nil {
+			return err
+		}
+	}
+	t.d.committed++
+	return nil
+}
+
+func (t *tx) Rollback() error {
+	t.d.mu.Lock()
+	t.d.rolled++
+	t.d.mu.Unlock()
+	return nil
+}
+
+var drivers int
+
+// open registers d under a unique name and opens a database with it.
+func open(t *testing.T, d *fake) *sql.DB {
+	drivers++
+	name := fmt.Sprintf("fake%d", drivers)
+	sql.Register(name, d)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func newRunner() *retrysql.Runner {
+	c := retry.NewCycler(backoff.Constant(0))
+	c.Limit(3)
+	return &retrysql.Runner{Cycler: c}
+}
+
+func TestRunner_InTx_Serializable(t *testing.T) {
+	d := &fake{commits: []error{stateError(retrysql.SerializationFailure)}}
+	db := open(t, d)
+
+	opts := &sql.TxOptions{Isolation: sql.LevelSerializable}
+	err := newRunner().InTx(context.Background(), db, opts, func(tx *sql.Tx) error {
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if d.committed != 1 {
+		t.Errorf("committed %d transactions, want 1", d.committed)
+	}
+}
+
+func TestRunner_InTx_ReadCommitted(t *testing.T) {
+	d := &fake{commits: []error{stateError(retrysql.SerializationFailure)}}
+	db := open(t, d)
+
+	opts := &sql.TxOptions{Isolation: sql.LevelReadCommitted}
+	err := newRunner().InTx(context.Background(), db, opts, func(tx *sql.Tx) error {
+		return nil
+	})
+
+	if retrysql.SQLState(err) != retrysql.SerializationFailure {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRunner_InTx_Rollback(t *testing.T) {
+	d := &fake{}
+	db := open(t, d)
+
+	attempts := 0
+	err := newRunner().InTx(context.Background(), db, nil, func(tx *sql.Tx) error {
+		attempts++
+		if attempts == 1 {
+			return fmt.Errorf("update: %w", stateError(retrysql.DeadlockDetected))
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if d.rolled != 1 || d.committed != 1 {
+		t.Errorf("rolled back %d and committed %d, want 1 and 1", d.rolled, d.committed)
+	}
+}
+
+func TestRunner_InTx_Replay(t *testing.T) {
+	errUnsafe := errors.New("unsafe")
+	d := &fake{}
+	db := open(t, d)
+
+	r := newRunner()
+	r.Replay = func(ctx context.Context, n int, err error) error {
+		if !retrysql.Deadlock(err) {
+			t.Errorf("unexpected error: %v", err)
+		}
+		return errUnsafe
+	}
+
+	err := r.InTx(context.Background(), db, nil, func(tx *sql.Tx) error {
+		return stateError(retrysql.DeadlockDetected)
+	})
+
+	if err != errUnsafe {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestInTx(t *testing.T) {
+	db := open(t, &fake{})
+
+	start := time.Now()
+	err := retrysql.InTx(context.Background(), db, nil, func(tx *sql.Tx) error {
+		return errors.New("Deadlock found when trying to get lock")
+	})
+
+	if err == nil {
+		t.Error("expected error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("elapsed %s, want bounded retries", elapsed)
+	}
+}
+
+func TestSerializationConflict(t *testing.T) {
+	for _, test := range []struct {
+		err error
+		exp bool
+	}{
+		{nil, false},
+		{stateError(retrysql.SerializationFailure), true},
+		{stateError(retrysql.DeadlockDetected), true},
+		{stateError("23505"), false},
+		{errors.New("could not serialize access due to concurrent update"), true},
+		{errors.New("connection refused"), false},
+	} {
+		if act := retrysql.SerializationConflict(test.err); act != test.exp {
+			t.Errorf("SerializationConflict(%v) = %t, want %t", test.err, act, test.exp)
+		}
+	}
+}
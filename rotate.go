@@ -0,0 +1,81 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"context"
+	"net"
+	"sort"
+)
+
+// A TargetFunc supplies the target of the n-th attempt of a retry cycle, such
+// as a network address or an endpoint. This way, retries rotate through the
+// replicas of a service instead of hammering the same dead one. An empty
+// string indicates that the attempt uses its default target.
+type TargetFunc func(ctx context.Context, n int) string
+
+// RoundRobin returns a [TargetFunc] that rotates through the given targets,
+// starting with the first one for the initial attempt. If no targets are
+// given, the default target is used.
+func RoundRobin(targets ...string) TargetFunc {
+	targets = append([]string(nil), targets...)
+	return func(_ context.Context, n int) string {
+		if len(targets) == 0 {
+			return ""
+		}
+		return targets[(n-1)%len(targets)]
+	}
+}
+
+// Lookup returns a [TargetFunc] that resolves host before each attempt, and
+// rotates through the addresses found, joined with port. The addresses are
+// sorted, such that consecutive attempts reach different addresses even if the
+// resolver shuffles them. If the lookup fails, host and port are returned as
+// is, leaving the resolution to the dialer. If resolver is nil,
+// [net.DefaultResolver] is used.
+func Lookup(resolver *net.Resolver, host, port string) TargetFunc {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return func(ctx context.Context, n int) string {
+		addrs, err := resolver.LookupHost(ctx, host)
+		if err != nil || len(addrs) == 0 {
+			return net.JoinHostPort(host, port)
+		}
+		sort.Strings(addrs)
+		return net.JoinHostPort(addrs[(n-1)%len(addrs)], port)
+	}
+}
+
+// Rotate registers fn to supply the target of each attempt scheduled with
+// [Cycler.Run]. Attempts obtain their target from the Target field of their
+// [Metadata]. Integrations that schedule attempts otherwise may consult
+// [Cycler.Target] instead. If fn is nil, no targets are supplied.
+func (c *Cycler) Rotate(fn TargetFunc) {
+	c.audit.touch()
+	c.target = fn
+}
+
+// Target returns the target of the n-th attempt of a retry cycle running in
+// ctx, as supplied by the function registered with [Cycler.Rotate]. It returns
+// an empty string if there is no such function.
+func (c *Cycler) Target(ctx context.Context, n int) string {
+	if c.target == nil {
+		return ""
+	}
+	return c.target(ctx, n)
+}
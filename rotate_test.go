@@ -0,0 +1,66 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestRoundRobin(t *testing.T) {
+	fn := retry.RoundRobin("a:80", "b:80", "c:80")
+
+	var act []string
+	for n := 1; n <= 4; n++ {
+		act = append(act, fn(context.Background(), n))
+	}
+	if exp := []string{"a:80", "b:80", "c:80", "a:80"}; !reflect.DeepEqual(act, exp) {
+		t.Errorf("targets were %q, want %q", act, exp)
+	}
+	if act := retry.RoundRobin()(context.Background(), 1); act != "" {
+		t.Errorf("target was %q, want none", act)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	fn := retry.Lookup(nil, "127.0.0.1", "8080")
+
+	if act, exp := fn(context.Background(), 2), "127.0.0.1:8080"; act != exp {
+		t.Errorf("target was %q, want %q", act, exp)
+	}
+}
+
+func TestCycler_Rotate(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Limit(3)
+	cycler.Rotate(retry.RoundRobin("a", "b"))
+
+	var act []string
+	_ = cycler.Run(context.Background(), func(ctx context.Context, n int) error {
+		m, _ := retry.MetadataFrom(ctx)
+		act = append(act, m.Target)
+		return ErrTest
+	})
+
+	if exp := []string{"a", "b", "a"}; !reflect.DeepEqual(act, exp) {
+		t.Errorf("targets were %q, want %q", act, exp)
+	}
+}
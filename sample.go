@@ -0,0 +1,85 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import "sync/atomic"
+
+// sampler selects every n-th failed attempt for telemetry.
+type sampler struct {
+	n     uint64 // sampling interval
+	count uint64 // number of failed attempts seen, accessed atomically
+}
+
+// pick reports whether the next failed attempt is sampled. The first attempt
+// is always sampled. A nil sampler samples all attempts.
+func (s *sampler) pick() bool {
+	if s == nil {
+		return true
+	}
+	return (atomic.AddUint64(&s.count, 1)-1)%s.n == 0
+}
+
+// Sample reduces the telemetry of failed attempts to 1 in n, keeping the
+// overhead bounded for cyclers that run millions of cycles per hour. Failed
+// attempts that are not sampled are not passed to error handlers, failure
+// handlers or instruments. The attempt that ends a retry cycle is always
+// passed on, as are successful attempts, exit handlers, notifiers and traces.
+// Sampling is shared by all cycles of the cycler. If n <= 1, all attempts are
+// passed on.
+//
+// Instruments implementing [SampledInstrument] observe sampled failures by
+// means of their variant for the sampling rate 1/n, such that they can scale
+// their counts up. Other instruments observe fewer failures and retries than
+// actually occurred, by a factor of about n.
+func (c *Cycler) Sample(n int) {
+	c.audit.touch()
+	if n <= 1 {
+		c.sample = nil
+		return
+	}
+	c.sample = &sampler{n: uint64(n)}
+}
+
+// A SampledInstrument is an [Instrument] that accounts for sampling, see
+// [Cycler.Sample]. Instruments registered with [Cycler.Instrument] that
+// implement this interface are detected automatically.
+type SampledInstrument interface {
+	Instrument
+	// Sampled returns the instrument that observes failed attempts sampled at
+	// the given rate in (0,1), along with the retries that follow them. This
+	// includes the categories of the failures if the instrument is a
+	// [CategoryInstrument]. Attempts that are passed on regardless of sampling
+	// are observed by the original instrument.
+	Sampled(rate float64) Instrument
+}
+
+// instruments returns instrs, with those implementing [SampledInstrument]
+// replaced by their variants for the sampling rate of s.
+func (s *sampler) instruments(instrs []Instrument) []Instrument {
+	if s == nil {
+		return instrs
+	}
+	rate := 1 / float64(s.n)
+	rated := make([]Instrument, len(instrs))
+	for i, in := range instrs {
+		if si, ok := in.(SampledInstrument); ok {
+			in = si.Sampled(rate)
+		}
+		rated[i] = in
+	}
+	return rated
+}
@@ -0,0 +1,101 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestCycler_Sample(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Limit(9)
+	cycler.Sample(3)
+
+	var errors, failures []int
+	cycler.OnError(func(n int, delay time.Duration, err error) {
+		errors = append(errors, n)
+	})
+	cycler.OnFailure(func(f retry.Failure) {
+		failures = append(failures, f.Attempt)
+	})
+
+	_ = cycler.Try(func(n int) error { return ErrTest })
+
+	if exp := []int{1, 4, 7}; !reflect.DeepEqual(errors, exp) {
+		t.Errorf("errors were reported for %v, want %v", errors, exp)
+	}
+	if exp := []int{1, 4, 7, 9}; !reflect.DeepEqual(failures, exp) {
+		t.Errorf("failures were reported for %v, want %v", failures, exp)
+	}
+}
+
+func TestCycler_Sample_Disabled(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Limit(3)
+	cycler.Sample(3)
+	cycler.Sample(1)
+
+	i := 0
+	cycler.OnError(func(n int, delay time.Duration, err error) { i++ })
+
+	_ = cycler.Try(func(n int) error { return ErrTest })
+
+	if i != 2 {
+		t.Errorf("i = %d, want 2", i)
+	}
+}
+
+// rated is a retry.SampledInstrument that records observations along with
+// their sampling rate.
+type rated struct {
+	rate float64
+	obs  *[]string
+}
+
+func (r rated) ObserveAttempt(n int, d time.Duration, err error) {
+	*r.obs = append(*r.obs, fmt.Sprintf("attempt %d @%g", n, r.rate))
+}
+
+func (r rated) ObserveDelay(n int, d time.Duration) {
+	*r.obs = append(*r.obs, fmt.Sprintf("delay %d @%g", n, r.rate))
+}
+
+func (r rated) Sampled(rate float64) retry.Instrument {
+	return rated{rate: rate, obs: r.obs}
+}
+
+func TestCycler_Sample_Instrument(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Limit(4)
+	cycler.Sample(4)
+
+	var obs []string
+	cycler.Instrument(rated{rate: 1, obs: &obs})
+
+	_ = cycler.Try(func(n int) error { return ErrTest })
+
+	exp := []string{"attempt 1 @0.25", "delay 1 @0.25", "attempt 4 @1"}
+	if !reflect.DeepEqual(obs, exp) {
+		t.Errorf("observations were %q, want %q", obs, exp)
+	}
+}
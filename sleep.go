@@ -0,0 +1,78 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"context"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+// A Sleeper waits for the delay between consecutive attempts of a retry cycle.
+// Custom implementations enable alternative wait mechanisms, such as test fakes
+// that return immediately.
+type Sleeper interface {
+	// Sleep blocks until the duration d has elapsed, and returns nil. If ctx is
+	// cancelled in the meantime, Sleep returns the error of ctx right away.
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// A SleeperFunc is the functional implementation of the [Sleeper] interface.
+type SleeperFunc func(ctx context.Context, d time.Duration) error
+
+func (f SleeperFunc) Sleep(ctx context.Context, d time.Duration) error {
+	return f(ctx, d)
+}
+
+// ClockSleeper returns the default [Sleeper], which waits by means of clock if
+// it implements [backoff.Timer], and by means of a system timer otherwise. No
+// timer is allocated for zero delays. If the context is already cancelled, the
+// sleeper returns its error without waiting, even if the delay is zero.
+func ClockSleeper(clock backoff.Clock) Sleeper {
+	return &clockSleeper{clock: clock}
+}
+
+type clockSleeper struct {
+	clock backoff.Clock
+}
+
+func (s *clockSleeper) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if d <= 0 {
+		// no need for a timer
+		return nil
+	}
+	// A fresh timer is used for every call and stopped on every path, which
+	// avoids resetting timers that might not have been drained.
+	var wake <-chan time.Time
+	if timer, ok := s.clock.(backoff.Timer); ok {
+		wake = timer.After(d)
+	} else {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		wake = t.C
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-wake:
+		return nil
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestCycler_Sleeper(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Hour))
+	cycler.Limit(3)
+
+	var slept time.Duration
+	cycler.Sleeper = retry.SleeperFunc(func(ctx context.Context, d time.Duration) error {
+		slept += d
+		return nil
+	})
+
+	_ = cycler.Try(func(n int) error { return ErrTest })
+
+	if exp := 2 * time.Hour; slept != exp {
+		t.Errorf("slept %s, want %s", slept, exp)
+	}
+}
+
+func TestClockSleeper_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s := retry.ClockSleeper(backoff.ClockFunc(time.Now))
+
+	if err := s.Sleep(ctx, time.Hour); err != context.Canceled {
+		t.Errorf("unexpected error: %#v", err)
+	}
+}
+
+func TestClockSleeper_Zero(t *testing.T) {
+	s := retry.ClockSleeper(backoff.ClockFunc(time.Now))
+
+	if err := s.Sleep(context.Background(), 0); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := s.Sleep(ctx, 0); err != context.Canceled {
+		t.Errorf("unexpected error: %#v", err)
+	}
+}
+
+func TestCycler_TryWithContext_ZeroDelay(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	const N = 3
+	err := cycler.TryWithContext(ctx, func(n int) error {
+		if n == N {
+			cancel()
+		} else if n > N {
+			t.Fatalf("too many attempts: n > %d", N)
+		}
+		return ErrTest
+	})
+
+	if err != context.Canceled {
+		t.Errorf("unexpected error: %#v", err)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import "sync/atomic"
+
+// Stats summarizes the retry cycles scheduled by a [Cycler] so far. Use
+// [Cycler.Stats] to obtain a snapshot.
+type Stats struct {
+	Started   uint64 // number of cycles started
+	Succeeded uint64 // number of cycles that succeeded
+	Exhausted uint64 // number of cycles that gave up after exceeding some limit
+	Aborted   uint64 // number of cycles that were cancelled or forced to exit
+	Attempts  uint64 // total number of attempts in cycles that succeeded
+}
+
+// Running returns the number of cycles that are still in progress.
+func (s Stats) Running() uint64 {
+	return s.Started - s.Succeeded - s.Exhausted - s.Aborted
+}
+
+// MeanAttempts returns the mean number of attempts it took for a cycle to
+// succeed. If no cycle has succeeded yet, the result is 0.
+func (s Stats) MeanAttempts() float64 {
+	if s.Succeeded == 0 {
+		return 0
+	}
+	return float64(s.Attempts) / float64(s.Succeeded)
+}
+
+// counters tracks the statistics of a cycler.
+type counters struct {
+	started   uint64
+	succeeded uint64
+	exhausted uint64
+	aborted   uint64
+	attempts  uint64
+}
+
+func (s *counters) start() {
+	atomic.AddUint64(&s.started, 1)
+}
+
+// end records the end of a cycle after n attempts.
+func (s *counters) end(reason StopReason, n int) {
+	switch reason {
+	case Succeeded:
+		atomic.AddUint64(&s.attempts, uint64(n))
+		atomic.AddUint64(&s.succeeded, 1)
+	case LimitReached, TimedOut:
+		atomic.AddUint64(&s.exhausted, 1)
+	default:
+		atomic.AddUint64(&s.aborted, 1)
+	}
+}
+
+func (s *counters) snapshot() Stats {
+	return Stats{
+		Started:   atomic.LoadUint64(&s.started),
+		Succeeded: atomic.LoadUint64(&s.succeeded),
+		Exhausted: atomic.LoadUint64(&s.exhausted),
+		Aborted:   atomic.LoadUint64(&s.aborted),
+		Attempts:  atomic.LoadUint64(&s.attempts),
+	}
+}
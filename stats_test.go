@@ -0,0 +1,65 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestCycler_Stats(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Limit(3)
+
+	// succeeds after 2 attempts
+	_ = cycler.Try(func(n int) error {
+		if n < 2 {
+			return ErrTest
+		}
+		return nil
+	})
+	// succeeds after 1 attempt
+	_ = cycler.Try(func(n int) error { return nil })
+	// gives up after 3 attempts
+	_ = cycler.Try(func(n int) error { return ErrTest })
+	// exits immediately
+	_ = cycler.Try(func(n int) error { return retry.ForceExit(ErrTest) })
+
+	act := cycler.Stats()
+	exp := retry.Stats{
+		Started:   4,
+		Succeeded: 2,
+		Exhausted: 1,
+		Aborted:   1,
+		Attempts:  3,
+	}
+
+	if act != exp {
+		t.Errorf("stats were %+v, want %+v", act, exp)
+	}
+
+	if m := act.MeanAttempts(); m != 1.5 {
+		t.Errorf("mean attempts was %f, want 1.5", m)
+	}
+
+	if r := act.Running(); r != 0 {
+		t.Errorf("running was %d, want 0", r)
+	}
+}
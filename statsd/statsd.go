@@ -0,0 +1,160 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package statsd emits retry metrics over the StatsD protocol, including the
+// tag extension of DogStatsD.
+package statsd
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deep-rent/retry"
+)
+
+// An Emitter is a [retry.Instrument] that writes StatsD metrics to an
+// [io.Writer], typically a UDP connection to the StatsD agent. Each metric is
+// written by a separate call to Write, such that it fits into one datagram.
+// The following metrics are emitted, prefixed by the configured prefix:
+//
+//   - attempt.success and attempt.failure count attempts by their outcome,
+//   - attempt.duration times the execution of attempts,
+//   - retry counts scheduled retries,
+//   - retry.<category> counts scheduled retries by the [retry.Category] of the
+//     failure that caused them,
+//   - delay times the delays before retries, and
+//   - cycle.<reason> counts retry cycles by their [retry.StopReason], with
+//     spaces in the reason replaced by underscores (requires [Emitter.Attach]).
+//
+// If tags are given, or if the cycler passed to [Emitter.Attach] has a name or
+// labels, they are appended to every metric in the DogStatsD format. Plain
+// StatsD servers may not accept such metrics. If the cycler samples failed
+// attempts (see [retry.Cycler.Sample]), the metrics observed for them carry
+// the sampling rate, such that the server scales their counts up. An emitter
+// is safe for concurrent use. Write errors are dropped.
+type Emitter struct {
+	out    *output
+	prefix string
+	tags   []string
+	rate   string // formatted sampling rate
+	suffix string // formatted tags
+}
+
+// output serializes writes to w.
+type output struct {
+	mu sync.Mutex // guards w
+	w  io.Writer
+}
+
+// New creates a new [Emitter] that writes to w. The prefix is prepended to the
+// name of each metric, separated by a dot unless empty. Tags are given in the
+// form "key:value".
+func New(w io.Writer, prefix string, tags ...string) *Emitter {
+	if prefix != "" {
+		prefix += "."
+	}
+	return newEmitter(&output{w: w}, prefix, tags)
+}
+
+func newEmitter(out *output, prefix string, tags []string) *Emitter {
+	var suffix string
+	if len(tags) > 0 {
+		suffix = "|#" + strings.Join(tags, ",")
+	}
+	return &Emitter{
+		out:    out,
+		prefix: prefix,
+		tags:   tags,
+		suffix: suffix,
+	}
+}
+
+// Attach registers the emitter with c, both as an instrument and as an exit
+// handler counting retry cycles. The name and labels of c are appended as tags,
+// using the key "policy" for the name. Labels are sorted by key.
+func (e *Emitter) Attach(c *retry.Cycler) {
+	tags := append([]string(nil), e.tags...)
+	if c.Name != "" {
+		tags = append(tags, "policy:"+c.Name)
+	}
+	keys := make([]string, 0, len(c.Labels))
+	for k := range c.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		tags = append(tags, k+":"+c.Labels[k])
+	}
+	d := newEmitter(e.out, e.prefix, tags)
+
+	c.Instrument(d)
+	c.OnExit(func(reason retry.StopReason, n int, err error) {
+		name := strings.ReplaceAll(reason.String(), " ", "_")
+		d.count("cycle." + name)
+	})
+}
+
+// Sampled implements [retry.SampledInstrument].
+func (e *Emitter) Sampled(rate float64) retry.Instrument {
+	if rate >= 1 {
+		return e
+	}
+	d := *e
+	d.rate = "|@" + strconv.FormatFloat(rate, 'f', -1, 64)
+	return &d
+}
+
+// ObserveAttempt implements [retry.Instrument].
+func (e *Emitter) ObserveAttempt(n int, d time.Duration, err error) {
+	if err == nil {
+		e.count("attempt.success")
+	} else {
+		e.count("attempt.failure")
+	}
+	e.timing("attempt.duration", d)
+}
+
+// ObserveDelay implements [retry.Instrument].
+func (e *Emitter) ObserveDelay(n int, d time.Duration) {
+	e.count("retry")
+	e.timing("delay", d)
+}
+
+// ObserveCategory implements [retry.CategoryInstrument].
+func (e *Emitter) ObserveCategory(n int, category retry.Category) {
+	e.count("retry." + category.String())
+}
+
+func (e *Emitter) count(name string) {
+	e.emit(name, "1", "c")
+}
+
+func (e *Emitter) timing(name string, d time.Duration) {
+	ms := float64(d) / float64(time.Millisecond)
+	e.emit(name, strconv.FormatFloat(ms, 'f', -1, 64), "ms")
+}
+
+// emit writes a single metric of the given type.
+func (e *Emitter) emit(name, value, typ string) {
+	line := e.prefix + name + ":" + value + "|" + typ + e.rate + e.suffix
+	e.out.mu.Lock()
+	defer e.out.mu.Unlock()
+	_, _ = io.WriteString(e.out.w, line)
+}
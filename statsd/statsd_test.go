@@ -0,0 +1,106 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statsd_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+	"github.com/deep-rent/retry/statsd"
+)
+
+// packets records each write as a separate packet.
+type packets []string
+
+func (p *packets) Write(b []byte) (int, error) {
+	*p = append(*p, string(b))
+	return len(b), nil
+}
+
+func TestEmitter(t *testing.T) {
+	var p packets
+	e := statsd.New(&p, "svc", "env:test")
+
+	e.ObserveAttempt(1, 1500*time.Microsecond, errors.New("test"))
+	e.ObserveDelay(1, 2*time.Second)
+	e.ObserveCategory(1, retry.CategoryServer)
+	e.ObserveAttempt(2, 0, nil)
+
+	exp := packets{
+		"svc.attempt.failure:1|c|#env:test",
+		"svc.attempt.duration:1.5|ms|#env:test",
+		"svc.retry:1|c|#env:test",
+		"svc.delay:2000|ms|#env:test",
+		"svc.retry.server:1|c|#env:test",
+		"svc.attempt.success:1|c|#env:test",
+		"svc.attempt.duration:0|ms|#env:test",
+	}
+	if !reflect.DeepEqual(p, exp) {
+		t.Errorf("packets were %q, want %q", p, exp)
+	}
+}
+
+func TestEmitter_Sampled(t *testing.T) {
+	var p packets
+	e := statsd.New(&p, "", "env:test")
+
+	e.Sampled(0.25).ObserveDelay(1, 2*time.Second)
+	e.Sampled(1).ObserveDelay(1, 2*time.Second)
+
+	exp := packets{
+		"retry:1|c|@0.25|#env:test",
+		"delay:2000|ms|@0.25|#env:test",
+		"retry:1|c|#env:test",
+		"delay:2000|ms|#env:test",
+	}
+	if !reflect.DeepEqual(p, exp) {
+		t.Errorf("packets were %q, want %q", p, exp)
+	}
+}
+
+func TestEmitter_Attach(t *testing.T) {
+	var p packets
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Limit(1)
+	statsd.New(&p, "").Attach(cycler)
+
+	_ = cycler.Try(func(n int) error { return errors.New("test") })
+
+	exp := "cycle.limit_reached:1|c"
+	if len(p) == 0 || p[len(p)-1] != exp {
+		t.Errorf("packets were %q, want last %q", p, exp)
+	}
+}
+
+func TestEmitter_Attach_Labels(t *testing.T) {
+	var p packets
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Name = "api"
+	cycler.Labels = retry.Labels{"team": "core", "env": "prod"}
+	statsd.New(&p, "", "host:a").Attach(cycler)
+
+	_ = cycler.Try(func(n int) error { return nil })
+
+	exp := "cycle.succeeded:1|c|#host:a,policy:api,env:prod,team:core"
+	if len(p) == 0 || p[len(p)-1] != exp {
+		t.Errorf("packets were %q, want last %q", p, exp)
+	}
+}
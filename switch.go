@@ -0,0 +1,69 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+// A SwitchFunc selects the backoff strategy for the next delay of a retry
+// cycle based on the error of the last attempt. It returns nil to select the
+// strategy the cycle started with. See [Cycler.SwitchOn].
+type SwitchFunc func(err error) backoff.Strategy
+
+// switcher is a backoff strategy that delegates to the strategy selected for
+// the last error.
+type switcher struct {
+	fn      SwitchFunc       // selects the strategy
+	base    backoff.Strategy // strategy the cycle started with
+	current backoff.Strategy // currently selected strategy
+}
+
+// observe selects the strategy for the next delay based on err.
+func (s *switcher) observe(err error) {
+	if next := s.fn(err); next != nil {
+		s.current = next
+	} else {
+		s.current = s.base
+	}
+}
+
+func (s *switcher) Delay(n int, start time.Time) time.Duration {
+	return s.current.Delay(n, start)
+}
+
+func (s *switcher) Explain(
+	n int,
+	start time.Time,
+) (time.Duration, backoff.Cause) {
+	return backoff.Explain(s.current, n, start)
+}
+
+// SwitchOn enables switching strategies in the middle of a retry cycle. After
+// each failed attempt, fn selects the strategy for the next delay based on the
+// error, e.g. to back off more patiently once connection errors give way to
+// rate-limit errors. The selected strategy receives the attempt count and
+// start time of the ongoing cycle, so elapsed time and attempts carry over
+// rather than starting anew. All other options, such as [Cycler.Limit] or
+// [Cycler.Jitter], apply to the selected strategy as well. If fn is nil,
+// cycles stick to the strategy chosen at their start.
+func (c *Cycler) SwitchOn(fn SwitchFunc) {
+	c.audit.touch()
+	c.handoff = fn
+}
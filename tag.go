@@ -0,0 +1,294 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+// TagName is the key of the struct tags read by [Decode].
+const TagName = "retry"
+
+// ParseTag parses a retry policy given in the compact notation of struct tags
+// into a [backoff.Strategy]. A policy starts with a base strategy, followed by
+// optional decorators, all separated by commas:
+//
+//	const(d)       constant delay d, see [backoff.Constant]
+//	linear(d,k)    delays growing by k, see [backoff.Linear]
+//	exp(d,m)       delays growing by factor m, see [backoff.Exponential]
+//	exp(d)         same as exp(d,2)
+//	jitter=spread  random jitter, see [backoff.Jitter]
+//	max=d          delay cap, see [backoff.Cap]
+//	limit=n        attempt limit, see [backoff.Limit]
+//	timeout=d      time limit, see [backoff.Timeout]
+//
+// Durations are given in the format accepted by [time.ParseDuration]. For
+// example, "exp(1s,2),max=30s,limit=5" describes exponential backoff starting
+// at one second, doubling with every retry, capped at 30 seconds, giving up
+// after five attempts. Decorators are applied in the canonical order of
+// [backoff.Builder].
+func ParseTag(tag string) (backoff.Strategy, error) {
+	s, err := parseTag(tag)
+	if err != nil {
+		return nil, fmt.Errorf("retry: invalid tag %q: %v", tag, err)
+	}
+	return s, nil
+}
+
+// parseTag implements ParseTag, but returns errors without context.
+func parseTag(tag string) (backoff.Strategy, error) {
+	terms := split(tag)
+	name, args, ok := call(terms[0])
+	if !ok {
+		return nil, fmt.Errorf("malformed strategy %q", terms[0])
+	}
+	arity := func(min, max int) error {
+		if len(args) < min || len(args) > max {
+			return fmt.Errorf("wrong number of arguments for %q", name)
+		}
+		return nil
+	}
+
+	var base backoff.Strategy
+	switch name {
+	case "const", "constant":
+		if err := arity(1, 1); err != nil {
+			return nil, err
+		}
+		d, err := time.ParseDuration(args[0])
+		if err != nil {
+			return nil, err
+		}
+		if base, err = backoff.NewConstant(d); err != nil {
+			return nil, err
+		}
+	case "lin", "linear":
+		if err := arity(2, 2); err != nil {
+			return nil, err
+		}
+		d, err := time.ParseDuration(args[0])
+		if err != nil {
+			return nil, err
+		}
+		k, err := time.ParseDuration(args[1])
+		if err != nil {
+			return nil, err
+		}
+		if base, err = backoff.NewLinear(d, k); err != nil {
+			return nil, err
+		}
+	case "exp", "exponential":
+		if err := arity(1, 2); err != nil {
+			return nil, err
+		}
+		d, err := time.ParseDuration(args[0])
+		if err != nil {
+			return nil, err
+		}
+		m := 2.0
+		if len(args) == 2 {
+			if m, err = strconv.ParseFloat(args[1], 64); err != nil {
+				return nil, err
+			}
+		}
+		if base, err = backoff.NewExponential(d, m); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown strategy %q", name)
+	}
+
+	b := backoff.Build(base)
+	for _, term := range terms[1:] {
+		k, v, ok := strings.Cut(term, "=")
+		if !ok {
+			return nil, fmt.Errorf("missing value for %q", k)
+		}
+		switch k {
+		case "jitter":
+			spread, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, err
+			}
+			// the builder panics on invalid spreads
+			if _, err := backoff.NewJitter(base, spread, nil); err != nil {
+				return nil, err
+			}
+			b.Jitter(spread)
+		case "max", "cap":
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, err
+			}
+			b.Cap(d)
+		case "limit":
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, err
+			}
+			b.Limit(n)
+		case "timeout":
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, err
+			}
+			b.Timeout(d)
+		default:
+			return nil, fmt.Errorf("unknown decorator %q", k)
+		}
+	}
+	return b.Strategy(), nil
+}
+
+// split splits s at the commas outside of parentheses, and trims the spaces
+// around each term.
+func split(s string) []string {
+	var terms []string
+	depth, i := 0, 0
+	for j, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				terms = append(terms, strings.TrimSpace(s[i:j]))
+				i = j + 1
+			}
+		}
+	}
+	return append(terms, strings.TrimSpace(s[i:]))
+}
+
+// call splits a term of the form name(arg1,arg2,...) into its parts.
+func call(term string) (name string, args []string, ok bool) {
+	name, rest, ok := strings.Cut(term, "(")
+	if !ok || !strings.HasSuffix(rest, ")") {
+		return "", nil, false
+	}
+	rest = strings.TrimSuffix(rest, ")")
+	for _, arg := range strings.Split(rest, ",") {
+		args = append(args, strings.TrimSpace(arg))
+	}
+	return strings.TrimSpace(name), args, true
+}
+
+var (
+	typeStrategy = reflect.TypeOf((*backoff.Strategy)(nil)).Elem()
+	typeCycler   = reflect.TypeOf((*Cycler)(nil))
+)
+
+// Decode fills in the retry policies of the struct that v points to, as
+// declared by the struct tags of its fields (see [TagName]). The tags are
+// parsed with [ParseTag]. A tagged field must either be a [backoff.Strategy],
+// which receives the parsed strategy, or a [*Cycler], which receives a new
+// cycler using that strategy. Fields that are already set are left untouched,
+// such that tags act as defaults. Untagged fields holding structs, or pointers
+// to structs, are decoded recursively. For example:
+//
+//	type Config struct {
+//		URL   string
+//		Retry *retry.Cycler `retry:"exp(100ms,2),jitter=0.5,max=10s,limit=5"`
+//	}
+//
+//	var config Config
+//	if err := retry.Decode(&config); err != nil {
+//		panic(err)
+//	}
+//	// config.Retry is now ready to use
+func Decode(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() ||
+		rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("retry: cannot decode into %T", v)
+	}
+	seen := map[visit]bool{{rv.Pointer(), rv.Type()}: true}
+	return decode(rv.Elem(), seen)
+}
+
+// visit identifies a pointer to a struct that has been decoded already.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// decode implements Decode for the struct v. Pointers in seen are not
+// followed again, which guards against cyclic references.
+func decode(v reflect.Value, seen map[visit]bool) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f, fv := t.Field(i), v.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag, ok := f.Tag.Lookup(TagName)
+		if !ok {
+			if err := nested(fv, seen); err != nil {
+				return err
+			}
+			continue
+		}
+		if f.Type != typeStrategy && f.Type != typeCycler {
+			return fmt.Errorf(
+				"retry: field %s.%s of type %s cannot hold a retry policy",
+				t, f.Name, f.Type,
+			)
+		}
+		if !fv.IsZero() {
+			continue
+		}
+		s, err := parseTag(tag)
+		if err != nil {
+			return fmt.Errorf(
+				"retry: invalid tag of field %s.%s: %v",
+				t, f.Name, err,
+			)
+		}
+		if f.Type == typeCycler {
+			fv.Set(reflect.ValueOf(NewCycler(s)))
+		} else {
+			fv.Set(reflect.ValueOf(&s).Elem())
+		}
+	}
+	return nil
+}
+
+// nested decodes v if it holds a struct, or a pointer to a struct that is not
+// in seen yet.
+func nested(v reflect.Value, seen map[visit]bool) error {
+	switch {
+	case v.Kind() == reflect.Struct:
+		return decode(v, seen)
+	case v.Kind() == reflect.Pointer && !v.IsNil() &&
+		v.Type() != typeCycler && v.Elem().Kind() == reflect.Struct:
+		k := visit{v.Pointer(), v.Type()}
+		if seen[k] {
+			return nil
+		}
+		seen[k] = true
+		return decode(v.Elem(), seen)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestParseTag(t *testing.T) {
+	start := time.Now()
+	for _, test := range []struct {
+		tag string
+		exp []time.Duration
+	}{
+		{"const(1s)", []time.Duration{1 * time.Second, 1 * time.Second}},
+		{"linear(1s, 2s)", []time.Duration{1 * time.Second, 3 * time.Second, 5 * time.Second}},
+		{"exp(1s)", []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second}},
+		{"exp(1s,3),max=5s", []time.Duration{1 * time.Second, 3 * time.Second, 5 * time.Second}},
+		{"exp(1s,2), limit=3", []time.Duration{1 * time.Second, 2 * time.Second, backoff.Exit}},
+	} {
+		s, err := retry.ParseTag(test.tag)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.tag, err)
+			continue
+		}
+		for i, exp := range test.exp {
+			if act := s.Delay(i+1, start); act != exp {
+				t.Errorf("%q: delay #%d was %s, want %s", test.tag, i+1, act, exp)
+			}
+		}
+	}
+}
+
+func TestParseTag_Invalid(t *testing.T) {
+	for _, tag := range []string{
+		"",
+		"exp",
+		"exp(1s",
+		"poly(1s)",
+		"const()",
+		"const(1s,2s)",
+		"exp(1s,x)",
+		"const(-1s)",
+		"const(1s),limit",
+		"const(1s),limit=x",
+		"const(1s),jitter=2",
+		"const(1s),jitter=NaN",
+		"exp(1s,-2)",
+		"linear(-1s,1s)",
+		"const(1s),retries=3",
+	} {
+		if _, err := retry.ParseTag(tag); err == nil {
+			t.Errorf("%q: expected error", tag)
+		}
+	}
+}
+
+type TestClientConfig struct {
+	URL      string
+	Strategy backoff.Strategy `retry:"const(1s),limit=2"`
+	Cycler   *retry.Cycler    `retry:"exp(100ms)"`
+	Nested   struct {
+		Strategy backoff.Strategy `retry:"const(2s)"`
+	}
+	Pointer *TestPoolConfig
+}
+
+type TestPoolConfig struct {
+	Cycler *retry.Cycler `retry:"const(3s)"`
+}
+
+func TestDecode(t *testing.T) {
+	preset := backoff.Constant(5 * time.Second)
+	config := TestClientConfig{Pointer: &TestPoolConfig{}}
+	config.Nested.Strategy = preset
+
+	if err := retry.Decode(&config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	start := time.Now()
+	if d := config.Strategy.Delay(3, start); d != backoff.Exit {
+		t.Errorf("delay was %s, want exit", d)
+	}
+	if config.Cycler == nil {
+		t.Error("cycler was not set")
+	}
+	if d := config.Nested.Strategy.Delay(1, start); d != 5*time.Second {
+		t.Errorf("preset strategy was overwritten")
+	}
+	if config.Pointer.Cycler == nil {
+		t.Error("nested cycler was not set")
+	}
+}
+
+type TestNode struct {
+	Cycler *retry.Cycler `retry:"const(1s)"`
+	Next   *TestNode
+}
+
+func TestDecode_Cycle(t *testing.T) {
+	a, b := &TestNode{}, &TestNode{}
+	a.Next, b.Next = b, a
+
+	if err := retry.Decode(a); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a.Cycler == nil || b.Cycler == nil {
+		t.Error("cycler was not set")
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	var config struct {
+		Retry time.Duration `retry:"const(1s)"`
+	}
+	if err := retry.Decode(&config); err == nil {
+		t.Error("expected error for field of wrong type")
+	}
+
+	var malformed struct {
+		Retry backoff.Strategy `retry:"const(1s"`
+	}
+	if err := retry.Decode(&malformed); err == nil {
+		t.Error("expected error for malformed tag")
+	}
+
+	if err := retry.Decode(TestClientConfig{}); err == nil {
+		t.Error("expected error for non-pointer")
+	}
+}
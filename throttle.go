@@ -0,0 +1,150 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+// ErrThrottled is returned if a retry cycle is rejected by a [Throttle].
+var ErrThrottled = errors.New("retry: throttled")
+
+// A Throttle permits at most n events per fixed time window. It is safe for
+// concurrent use. Use [NewThrottle] to create a new throttle.
+//
+// The Clock of a throttle determines the current time. If nil, the system
+// clock is used. A Clock that also implements [backoff.Timer] controls how long
+// [Throttle.Wait] blocks. Cyclers consult the throttle by means of their own
+// Clock and Sleeper instead.
+type Throttle struct {
+	Clock  backoff.Clock
+	mu     sync.Mutex
+	n      int           // maximum number of events per window
+	window time.Duration // length of a window
+	start  time.Time     // start of the current window
+	count  int           // number of events in the current window
+}
+
+// NewThrottle creates a new [Throttle] that permits at most n events per time
+// window. The function panics if n < 1 or window <= 0.
+func NewThrottle(n int, window time.Duration) *Throttle {
+	switch {
+	case n < 1:
+		panic(fmt.Sprintf("n = %d, must be >= 1", n))
+	case window <= 0:
+		panic(fmt.Sprintf("window = %s, must be > 0", window))
+	}
+	return &Throttle{
+		n:      n,
+		window: window,
+	}
+}
+
+// clock returns the Clock of t, or the system clock if there is none.
+func (t *Throttle) clock() backoff.Clock {
+	if t.Clock == nil {
+		return now
+	}
+	return t.Clock
+}
+
+// reserve tries to register an event of the given cost at time now. If the
+// throttle is exhausted, it returns the time left until the next window starts.
+func (t *Throttle) reserve(
+	now time.Time,
+	cost int,
+) (ok bool, wait time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if now.Sub(t.start) >= t.window {
+		t.start = now
+		t.count = 0
+	}
+	if t.count+cost <= t.n {
+		t.count += cost
+		return true, 0
+	}
+	return false, t.start.Add(t.window).Sub(now)
+}
+
+// Allow registers an event and reports whether it is permitted. Events that are
+// not permitted do not count towards the limit.
+func (t *Throttle) Allow() bool {
+	return t.AllowN(1)
+}
+
+// AllowN works like [Throttle.Allow], but the event counts cost times towards
+// the limit. This way, one throttle can arbitrate fairly between cheap and
+// expensive events. Events whose cost exceeds the limit are never permitted.
+// The function panics if cost < 1.
+func (t *Throttle) AllowN(cost int) bool {
+	if cost < 1 {
+		panic(fmt.Sprintf("cost = %d, must be >= 1", cost))
+	}
+	return t.allow(t.clock().Time(), cost)
+}
+
+// allow implements [Throttle.AllowN] for an event at time now.
+func (t *Throttle) allow(now time.Time, cost int) bool {
+	ok, _ := t.reserve(now, cost)
+	return ok
+}
+
+// Wait blocks until an event is permitted, and then registers it. It returns
+// early with the error of ctx if ctx is cancelled in the meantime.
+func (t *Throttle) Wait(ctx context.Context) error {
+	return t.WaitN(ctx, 1)
+}
+
+// WaitN works like [Throttle.Wait], but the event counts cost times towards
+// the limit. It returns [ErrThrottled] right away if cost exceeds the limit,
+// since such an event is never permitted. The function panics if cost < 1.
+func (t *Throttle) WaitN(ctx context.Context, cost int) error {
+	clock := t.clock()
+	return t.wait(ctx, cost, clock, ClockSleeper(clock))
+}
+
+// wait implements [Throttle.WaitN], reading the time from clock and waiting by
+// means of sleeper.
+func (t *Throttle) wait(
+	ctx context.Context,
+	cost int,
+	clock backoff.Clock,
+	sleeper Sleeper,
+) error {
+	if cost < 1 {
+		panic(fmt.Sprintf("cost = %d, must be >= 1", cost))
+	}
+	if cost > t.n {
+		return ErrThrottled
+	}
+	for {
+		ok, wait := t.reserve(clock.Time(), cost)
+		if ok {
+			return nil
+		}
+		if err := sleeper.Sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestThrottle(t *testing.T) {
+	th := retry.NewThrottle(2, time.Hour)
+
+	for i, exp := range []bool{true, true, false, false} {
+		if act := th.Allow(); act != exp {
+			t.Errorf("event #%d allowed: %t, want %t", i+1, act, exp)
+		}
+	}
+}
+
+func TestThrottle_Wait(t *testing.T) {
+	const W = 20 * time.Millisecond
+	th := retry.NewThrottle(1, W)
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	v := &virtual{now: start}
+	th.Clock = v
+
+	for i := 0; i < 2; i++ {
+		if err := th.Wait(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if d := v.now.Sub(start); d != W {
+		t.Errorf("waited %s, want %s", d, W)
+	}
+}
+
+func TestCycler_Throttle(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Throttle(1, time.Hour, false)
+
+	succeed := func(n int) error { return nil }
+
+	if err := cycler.Try(succeed); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := cycler.Try(succeed); err != retry.ErrThrottled {
+		t.Errorf("unexpected error: %#v", err)
+	}
+}
+
+func TestCycler_Throttle_Block(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	v := &virtual{now: start}
+
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Clock = v
+	cycler.Throttle(1, time.Hour, true)
+
+	succeed := func(n int) error { return nil }
+	_ = cycler.Try(succeed)
+
+	if err := cycler.Try(succeed); err != nil {
+		t.Errorf("unexpected error: %#v", err)
+	}
+	if d := v.now.Sub(start); d != time.Hour {
+		t.Errorf("waited %s, want %s", d, time.Hour)
+	}
+}
+
+func TestCycler_ThrottleRetries(t *testing.T) {
+	th := retry.NewThrottle(3, time.Hour)
+
+	// both cyclers share the same retry budget
+	c1 := retry.NewCycler(backoff.Constant(0))
+	c1.ThrottleRetries(th, false)
+	c2 := retry.NewCycler(backoff.Constant(0))
+	c2.ThrottleRetries(th, false)
+
+	var reason retry.StopReason
+	c2.OnExit(func(r retry.StopReason, n int, err error) { reason = r })
+
+	attempts := 0
+	_ = c1.Try(func(n int) error {
+		attempts++
+		if n < 3 {
+			return ErrTest
+		}
+		return nil
+	})
+	err := c2.Try(func(n int) error {
+		attempts++
+		return ErrTest
+	})
+
+	if !errors.Is(err, ErrTest) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if reason != retry.LimitReached {
+		t.Errorf("reason was %s, want %s", reason, retry.LimitReached)
+	}
+	if exp := 3 + 2; attempts != exp {
+		t.Errorf("got %d attempts, want %d", attempts, exp)
+	}
+}
+
+func TestCycler_ThrottleRetries_Block(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	v := &virtual{now: start}
+
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Clock = v
+	cycler.Limit(3)
+	cycler.ThrottleRetries(retry.NewThrottle(1, time.Hour), true)
+
+	attempts := 0
+	err := cycler.Try(func(n int) error {
+		attempts++
+		return ErrTest
+	})
+
+	if !errors.Is(err, ErrTest) {
+		t.Errorf("unexpected error: %#v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("got %d attempts, want 3", attempts)
+	}
+	// the second retry waits for the next window
+	if d := v.now.Sub(start); d != time.Hour {
+		t.Errorf("waited %s, want %s", d, time.Hour)
+	}
+}
+
+func TestThrottle_AllowN(t *testing.T) {
+	th := retry.NewThrottle(5, time.Hour)
+
+	tests := []struct {
+		cost int
+		exp  bool
+	}{
+		{2, true},
+		{2, true},
+		{2, false},
+		{1, true},
+		{6, false},
+	}
+
+	for i, test := range tests {
+		if act := th.AllowN(test.cost); act != test.exp {
+			t.Errorf("event #%d allowed: %t, want %t", i+1, act, test.exp)
+		}
+	}
+}
+
+func TestThrottle_WaitN_Exceeded(t *testing.T) {
+	th := retry.NewThrottle(2, time.Hour)
+
+	if err := th.WaitN(context.Background(), 3); err != retry.ErrThrottled {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCycler_RetryCost(t *testing.T) {
+	th := retry.NewThrottle(4, time.Hour)
+
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.ThrottleRetries(th, false)
+	cycler.RetryCost(2)
+
+	attempts := 0
+	_ = cycler.Try(func(n int) error {
+		attempts++
+		return ErrTest
+	})
+
+	if exp := 1 + 2; attempts != exp {
+		t.Errorf("got %d attempts, want %d", attempts, exp)
+	}
+}
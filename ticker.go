@@ -0,0 +1,66 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"context"
+	"time"
+
+	"github.com/deep-rent/retry/backoff"
+)
+
+// Ticker returns a channel that delivers the current time at intervals shaped
+// by strategy, for polling loops that follow a backoff curve without modelling
+// each poll as a failure, e.g. when watching for a resource to appear. The
+// n-th tick is delivered after the n-th delay of strategy has passed since the
+// previous tick was received, so slow receivers never miss a tick. Decorate
+// strategy to add jitter, caps or limits. The channel is closed once strategy
+// signals [backoff.Exit], or ctx is cancelled.
+//
+// Time is read from clock, and delays are waited out by means of
+// [ClockSleeper], such that a [backoff.Timer] drives the ticks. If clock is
+// nil, the system clock is used.
+func Ticker(
+	ctx context.Context,
+	strategy backoff.Strategy,
+	clock backoff.Clock,
+) <-chan time.Time {
+	if clock == nil {
+		clock = now
+	}
+	sleeper := ClockSleeper(clock)
+	ch := make(chan time.Time)
+	go func() {
+		defer close(ch)
+		start := clock.Time()
+		for n := 1; ; n++ {
+			d := strategy.Delay(n, start)
+			if d == backoff.Exit {
+				return
+			}
+			if err := sleeper.Sleep(ctx, d); err != nil {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- clock.Time():
+			}
+		}
+	}()
+	return ch
+}
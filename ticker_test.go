@@ -0,0 +1,77 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestTicker(t *testing.T) {
+	s := backoff.Limit(backoff.Linear(1*time.Millisecond, 1*time.Millisecond), 4)
+
+	n := 0
+	for range retry.Ticker(context.Background(), s, nil) {
+		n++
+	}
+
+	if n != 3 {
+		t.Errorf("got %d ticks, want 3", n)
+	}
+}
+
+func TestTicker_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch := retry.Ticker(ctx, backoff.Constant(1*time.Millisecond), nil)
+	<-ch
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		for ok {
+			_, ok = <-ch
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("channel was not closed")
+	}
+}
+
+func TestTicker_Clock(t *testing.T) {
+	t0 := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	v := &virtual{now: t0}
+	s := backoff.Limit(backoff.Linear(1*time.Hour, 1*time.Hour), 4)
+
+	var ticks []time.Duration
+	start := time.Now()
+	for tick := range retry.Ticker(context.Background(), s, v) {
+		ticks = append(ticks, tick.Sub(t0))
+	}
+
+	exp := []time.Duration{1 * time.Hour, 3 * time.Hour, 6 * time.Hour}
+	if !reflect.DeepEqual(ticks, exp) {
+		t.Errorf("ticks were at %v, want %v", ticks, exp)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("ticker took %s in real time", d)
+	}
+}
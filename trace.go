@@ -0,0 +1,104 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// A Span describes a single attempt within a traced retry cycle.
+type Span struct {
+	Attempt int           // attempt count, starting at 1
+	Start   time.Time     // time at which the attempt started
+	End     time.Time     // time at which the attempt returned
+	Delay   time.Duration // delay that followed, or 0 if there was no retry
+	Err     error         // error returned by the attempt, if any
+}
+
+// MarshalJSON encodes s as a JSON object. The error is represented by its
+// message, and both the error and the delay are omitted if not present.
+func (s Span) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Attempt int       `json:"attempt"`
+		Start   time.Time `json:"start"`
+		End     time.Time `json:"end"`
+		Delay   string    `json:"delay,omitempty"`
+		Error   string    `json:"error,omitempty"`
+	}{
+		Attempt: s.Attempt,
+		Start:   s.Start,
+		End:     s.End,
+	}
+	if s.Delay > 0 {
+		v.Delay = s.Delay.String()
+	}
+	if s.Err != nil {
+		v.Error = s.Err.Error()
+	}
+	return json.Marshal(v)
+}
+
+// A Trace captures the full timeline of a retry cycle. Unlike
+// [Cycler.History], which keeps a bounded number of failures, a trace records
+// every attempt, including the successful one. Use [WithTrace] to enable
+// tracing for a particular cycle.
+type Trace struct {
+	Start  time.Time  // time at which the cycle started
+	End    time.Time  // time at which the cycle ended
+	Seed   int64      // seed used for jitter, see [Cycler.Seed]
+	Reason StopReason // tells why the cycle has ended
+	Spans  []Span     // attempts in chronological order
+}
+
+// MarshalJSON encodes t as a JSON object, such that the retry history of a
+// failed operation can be attached to bug reports.
+func (t *Trace) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Start  time.Time `json:"start"`
+		End    time.Time `json:"end"`
+		Seed   int64     `json:"seed"`
+		Reason string    `json:"reason"`
+		Spans  []Span    `json:"spans"`
+	}{
+		Start:  t.Start,
+		End:    t.End,
+		Seed:   t.Seed,
+		Reason: t.Reason.String(),
+		Spans:  t.Spans,
+	})
+}
+
+// traceKey is the context key under which a [Trace] is stored.
+type traceKey struct{}
+
+// WithTrace returns a copy of ctx that makes the retry cycle run with it
+// record its timeline into t. The trace is populated as the cycle proceeds
+// and complete once the cycle has returned. It must not be shared among cycles
+// that run concurrently. Cycles nested in the traced cycle through the context
+// passed to attempts by [Cycler.Run] are not traced, unless their context is
+// given a trace of its own.
+func WithTrace(ctx context.Context, t *Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, t)
+}
+
+// traceFrom extracts the [Trace] stored in ctx, if any.
+func traceFrom(ctx context.Context) *Trace {
+	t, _ := ctx.Value(traceKey{}).(*Trace)
+	return t
+}
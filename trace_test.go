@@ -0,0 +1,151 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestWithTrace(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Millisecond))
+	cycler.Seed(42)
+
+	var trace retry.Trace
+	ctx := retry.WithTrace(context.Background(), &trace)
+	err := cycler.TryWithContext(ctx, func(n int) error {
+		if n < 3 {
+			return ErrTest
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if trace.Reason != retry.Succeeded || trace.Seed != 42 {
+		t.Errorf("unexpected trace: %+v", trace)
+	}
+	if trace.End.Before(trace.Start) {
+		t.Errorf("trace ended before it started")
+	}
+	if len(trace.Spans) != 3 {
+		t.Fatalf("got %d spans, want 3", len(trace.Spans))
+	}
+	for i, s := range trace.Spans {
+		if s.Attempt != i+1 {
+			t.Errorf("#%d: attempt was %d, want %d", i, s.Attempt, i+1)
+		}
+		last := i == len(trace.Spans)-1
+		if (s.Err == nil) != last {
+			t.Errorf("#%d: unexpected error: %v", i, s.Err)
+		}
+		if exp := 1 * time.Millisecond; !last && s.Delay != exp {
+			t.Errorf("#%d: delay was %s, want %s", i, s.Delay, exp)
+		}
+	}
+}
+
+func TestWithTrace_Nested(t *testing.T) {
+	outer := retry.NewCycler(backoff.Constant(0))
+	outer.Limit(3)
+	inner := retry.NewCycler(backoff.Constant(0))
+
+	var trace retry.Trace
+	ctx := retry.WithTrace(context.Background(), &trace)
+	before := time.Now()
+	err := outer.Run(ctx, func(ctx context.Context, n int) error {
+		return inner.Run(ctx, func(context.Context, int) error {
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if trace.Reason != retry.Succeeded || trace.Start.Before(before) {
+		t.Errorf("unexpected trace: %+v", trace)
+	}
+	if len(trace.Spans) != 1 {
+		t.Errorf("got %d spans, want 1", len(trace.Spans))
+	}
+
+	err = outer.Run(ctx, func(ctx context.Context, n int) error {
+		_ = inner.Run(ctx, func(context.Context, int) error {
+			return nil
+		})
+		return ErrTest
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if trace.Reason != retry.LimitReached {
+		t.Errorf("reason was %s, want %s", trace.Reason, retry.LimitReached)
+	}
+	if trace.End.Before(trace.Start) {
+		t.Errorf("trace ended before it started")
+	}
+	if len(trace.Spans) != 3 {
+		t.Fatalf("got %d spans, want 3", len(trace.Spans))
+	}
+	for i, s := range trace.Spans {
+		if s.Attempt != i+1 || s.Err == nil {
+			t.Errorf("#%d: unexpected span: %+v", i, s)
+		}
+		if s.Start.Before(trace.Start) {
+			t.Errorf("#%d: span started before the trace", i)
+		}
+	}
+}
+
+func TestTrace_MarshalJSON(t *testing.T) {
+	t0 := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	trace := &retry.Trace{
+		Start:  t0,
+		End:    t0.Add(3 * time.Second),
+		Seed:   7,
+		Reason: retry.LimitReached,
+		Spans: []retry.Span{{
+			Attempt: 1,
+			Start:   t0,
+			End:     t0.Add(1 * time.Second),
+			Delay:   2 * time.Second,
+			Err:     ErrTest,
+		}},
+	}
+
+	b, err := json.Marshal(trace)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	act := string(b)
+	exp := strings.Join([]string{
+		`{"start":"2022-01-01T00:00:00Z","end":"2022-01-01T00:00:03Z",`,
+		`"seed":7,"reason":"limit reached","spans":[{"attempt":1,`,
+		`"start":"2022-01-01T00:00:00Z","end":"2022-01-01T00:00:01Z",`,
+		`"delay":"2s","error":"test"}]}`,
+	}, "")
+	if act != exp {
+		t.Errorf("json was %s, want %s", act, exp)
+	}
+}
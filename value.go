@@ -0,0 +1,50 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import "context"
+
+// A ValueAttemptFunc is an [AttemptFunc] that produces a value of type T.
+type ValueAttemptFunc[T any] func(n int) (T, error)
+
+// TryValue works like [Cycler.Try], but returns the value produced by the
+// attempt that succeeded. If the cycle fails, the zero value of T is returned
+// along with the error, even if some attempt returned a value before failing.
+func TryValue[T any](c *Cycler, attempt ValueAttemptFunc[T]) (T, error) {
+	return TryValueWithContext(context.Background(), c, attempt)
+}
+
+// TryValueWithContext works like [Cycler.TryWithContext], but returns the
+// value produced by the attempt that succeeded. If the cycle fails, the zero
+// value of T is returned along with the error.
+func TryValueWithContext[T any](
+	ctx context.Context,
+	c *Cycler,
+	attempt ValueAttemptFunc[T],
+) (T, error) {
+	var v T
+	err := c.TryWithContext(ctx, func(n int) error {
+		var err error
+		v, err = attempt(n)
+		return err
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return v, nil
+}
@@ -0,0 +1,79 @@
+/*
+Copyright (c) 2022 deep.rent GmbH (https://deep.rent)
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deep-rent/retry"
+	"github.com/deep-rent/retry/backoff"
+)
+
+func TestTryValue(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Limit(3)
+
+	v, err := retry.TryValue(cycler, func(n int) (int, error) {
+		if n < 3 {
+			return n, ErrTest
+		}
+		return n * 10, nil
+	})
+
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if v != 30 {
+		t.Errorf("value was %d, want 30", v)
+	}
+}
+
+func TestTryValue_Failure(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(0))
+	cycler.Limit(2)
+
+	v, err := retry.TryValue(cycler, func(n int) (string, error) {
+		return "partial", ErrTest
+	})
+
+	if !errors.Is(err, ErrTest) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if v != "" {
+		t.Errorf("value was %q, want zero value", v)
+	}
+}
+
+func TestTryValueWithContext(t *testing.T) {
+	cycler := retry.NewCycler(backoff.Constant(1 * time.Hour))
+	ctx, cancel := context.WithCancel(context.Background())
+
+	v, err := retry.TryValueWithContext(ctx, cycler, func(n int) (*int, error) {
+		cancel()
+		return &n, ErrTest
+	})
+
+	if err != context.Canceled {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if v != nil {
+		t.Errorf("value was %v, want nil", v)
+	}
+}